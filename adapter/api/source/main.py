@@ -22,9 +22,9 @@ def get_inference(token):
 @app.route('/forecast', methods=['GET'])
 def get_forecast():
     node_values = [
-        NodeValue("Worker1", str(random.uniform(0.0, 100.0))),
-        NodeValue("Worker2", str(random.uniform(0.0, 100.0))),
-        NodeValue("Worker3", str(random.uniform(0.0, 100.0))),
+        NodeValue("allo1qyqszqgpqyqszqgpqyqszqgpqyqszqgpuqma69", str(random.uniform(0.0, 100.0))),
+        NodeValue("allo1qgpqyqszqgpqyqszqgpqyqszqgpqyqszdyac3n", str(random.uniform(0.0, 100.0))),
+        NodeValue("allo1qvpsxqcrqvpsxqcrqvpsxqcrqvpsxqcrv5uemj", str(random.uniform(0.0, 100.0))),
     ]
     return jsonify([nv.__dict__ for nv in node_values])
 
@@ -0,0 +1,129 @@
+package api_worker_reputer
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const DEFAULT_API_KEY_HEADER = "X-API-Key"
+
+// Request to an endpoint, configured by the parameters of its role, prefixed by the role:
+// e.g. `InferenceEndpoint`, `InferenceMethod`, `InferenceHeaders`, `InferenceBody` and `InferenceResultPath`.
+// Placeholders are expanded in the url, header values and body. Secret references are kept until the request is built,
+// so they are not logged.
+type endpointRequest struct {
+	method     string
+	url        string
+	headers    map[string]string
+	body       string
+	resultPath string // selector of the value in JSON responses, the whole body if empty
+}
+
+// Request to the endpoint of the role configured in the parameters
+func endpointRequestOf(params map[string]string, prefix string, blockHeight int64, topicId uint64) (endpointRequest, error) {
+	expand := func(template string) string {
+		return lib.ReplaceExtendedPlaceholders(template, params, blockHeight, topicId)
+	}
+	request := endpointRequest{
+		method:     strings.ToUpper(params[prefix+"Method"]),
+		url:        expand(params[prefix+"Endpoint"]),
+		headers:    map[string]string{},
+		body:       expand(params[prefix+"Body"]),
+		resultPath: params[prefix+"ResultPath"],
+	}
+	if request.url == "" {
+		return endpointRequest{}, fmt.Errorf("no %sEndpoint provided", prefix)
+	}
+	switch request.method {
+	case "":
+		request.method = http.MethodGet
+		if request.body != "" {
+			request.method = http.MethodPost
+		}
+	case http.MethodGet, http.MethodPost:
+	default:
+		return endpointRequest{}, fmt.Errorf("unsupported %sMethod %s, expected GET or POST", prefix, request.method)
+	}
+
+	if headers := params[prefix+"Headers"]; headers != "" {
+		var parsed map[string]string
+		if err := json.Unmarshal([]byte(headers), &parsed); err != nil {
+			return endpointRequest{}, fmt.Errorf("%sHeaders must be a JSON object of strings: %w", prefix, err)
+		}
+		for name, value := range parsed {
+			request.headers[name] = expand(value)
+		}
+	}
+	if token := params[prefix+"BearerToken"]; token != "" {
+		request.headers["Authorization"] = "Bearer " + token
+	}
+	if key := params[prefix+"ApiKey"]; key != "" {
+		header := params[prefix+"ApiKeyHeader"]
+		if header == "" {
+			header = DEFAULT_API_KEY_HEADER
+		}
+		request.headers[header] = key
+	}
+	return request, nil
+}
+
+// Build the http request, resolving secret references
+func (r endpointRequest) httpRequest(ctx context.Context) (*http.Request, error) {
+	url, err := resolveSecrets(r.url)
+	if err != nil {
+		return nil, err
+	}
+	body, err := resolveSecrets(r.body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to %s: %w", r.url, err)
+	}
+	if body == "" {
+		req.Body, req.ContentLength = http.NoBody, 0
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range r.headers {
+		value, err := resolveSecrets(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// Secret reference: `{env:NAME}` for the environment variable NAME, `{file:PATH}` for the trimmed content of the file
+var secretReference = regexp.MustCompile(`\{(env|file):([^{}]+)\}`)
+
+// Replace the secret references of the template by the secrets
+func resolveSecrets(template string) (string, error) {
+	var err error
+	resolved := secretReference.ReplaceAllStringFunc(template, func(reference string) string {
+		match := secretReference.FindStringSubmatch(reference)
+		switch match[1] {
+		case "env":
+			value, ok := os.LookupEnv(match[2])
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %s is not set", match[2])
+			}
+			return value
+		default:
+			content, readErr := os.ReadFile(match[2])
+			if readErr != nil && err == nil {
+				err = fmt.Errorf("failed to read secret file: %w", readErr)
+			}
+			return strings.TrimSpace(string(content))
+		}
+	})
+	return resolved, err
+}
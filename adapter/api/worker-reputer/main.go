@@ -3,58 +3,81 @@ package api_worker_reputer
 import (
 	"allora_offchain_node/lib"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 
 	alloraMath "github.com/allora-network/allora-chain/math"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/rs/zerolog/log"
 )
 
+const adapterName = "api-worker-reputer"
+
+func init() {
+	parameters := []lib.AdapterParameter{}
+	for _, endpoint := range []struct {
+		prefix     string
+		capability lib.AdapterCapability
+	}{
+		{"Inference", lib.AdapterCapabilityInference},
+		{"Forecast", lib.AdapterCapabilityForecast},
+		{"GroundTruth", lib.AdapterCapabilityGroundTruth},
+	} {
+		role := []lib.AdapterCapability{endpoint.capability}
+		parameters = append(parameters,
+			lib.AdapterParameter{Name: endpoint.prefix + "Endpoint", Description: "url template of the endpoint", Required: true, Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "Method", Description: "GET, or POST, the default with a body", Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "Headers", Description: "JSON object of header templates", Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "Body", Description: "template of the JSON request body", Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "BearerToken", Description: "bearer token, e.g. {env:NAME} or {file:PATH}", Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "ApiKey", Description: "API key, e.g. {env:NAME} or {file:PATH}", Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "ApiKeyHeader", Description: "header of the API key, X-API-Key by default", Capabilities: role},
+			lib.AdapterParameter{Name: endpoint.prefix + "ResultPath", Description: "path of the value in JSON responses, e.g. data.predictions[0]", Capabilities: role},
+		)
+	}
+	lib.RegisterAdapter(lib.AdapterRegistration{
+		Name: adapterName,
+		New:  func() lib.Adapter { return NewAlloraAdapter() },
+		Parameters: append(parameters,
+			lib.AdapterParameter{Name: "LossFunctionService", Description: "base url of the loss function service", Required: true, Capabilities: []lib.AdapterCapability{lib.AdapterCapabilityLossFunction}},
+		),
+	})
+}
+
 type AlloraAdapter struct {
 	name string
+	// Loss function services found not to support batches, by url
+	batchUnsupported sync.Map
 }
 
 func (a *AlloraAdapter) Name() string {
 	return a.name
 }
 
-func replacePlaceholders(urlTemplate string, params map[string]string) string {
-	for key, value := range params {
-		placeholder := fmt.Sprintf("{%s}", key)
-		urlTemplate = strings.ReplaceAll(urlTemplate, placeholder, value)
+// Request the endpoint, returning the body, or the value at the result path of JSON bodies.
+// GET requests, and others with an Idempotency-Key header, are retried by the shared adapter http client.
+func requestEndpoint(ctx context.Context, endpoint endpointRequest) (string, error) {
+	req, err := endpoint.httpRequest(ctx)
+	if err != nil {
+		return "", err
 	}
-	return urlTemplate
-}
-
-// Replace placeholders and also the blockheheight
-func replaceExtendedPlaceholders(urlTemplate string, params map[string]string, blockHeight int64, topicId uint64) string {
-	// Create a map of default parameters
-	blockHeightAsString := strconv.FormatInt(blockHeight, 10)
-	topicIdAsString := strconv.FormatUint(topicId, 10)
-	defaultParams := map[string]string{
-		"BlockHeight": blockHeightAsString,
-		"TopicId":     topicIdAsString,
-	}
-	urlTemplate = replacePlaceholders(urlTemplate, defaultParams)
-	urlTemplate = replacePlaceholders(urlTemplate, params)
-	return urlTemplate
-}
-
-func requestEndpoint(url string) (string, error) {
-	// make request to url
-	resp, err := http.Get(url)
+	idempotent := req.Method == http.MethodGet || req.Header.Get("Idempotency-Key") != ""
+	resp, err := lib.AdapterHttp().Do(req, idempotent)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request to %s: %w", url, err)
+		return "", fmt.Errorf("failed to make request to %s: %w", endpoint.url, err)
 	}
 	defer resp.Body.Close()
 
 	// Check if the response status is OK
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("received non-OK HTTP status %d", resp.StatusCode)
+		return "", &lib.HttpStatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Read the response body
@@ -64,54 +87,109 @@ func requestEndpoint(url string) (string, error) {
 	}
 
 	log.Info().Bytes("body", body).Msg("Requested endpoint")
-	// convert bytes to string
-	return string(body), nil
+	if endpoint.resultPath == "" {
+		// convert bytes to string
+		return string(body), nil
+	}
+	value, err := selectJSONPath(body, endpoint.resultPath)
+	if err != nil {
+		return "", &lib.InvalidValueError{Source: endpoint.url, Value: string(body), Err: fmt.Errorf("failed to select %s: %w", endpoint.resultPath, err)}
+	}
+	return value, nil
 }
 
 // Expects an inference as a string scalar value
-func (a *AlloraAdapter) CalcInference(node lib.WorkerConfig, blockHeight int64) (string, error) {
-	urlTemplate := node.Parameters["InferenceEndpoint"]
-	url := replaceExtendedPlaceholders(urlTemplate, node.Parameters, blockHeight, node.TopicId)
-	log.Debug().Str("url", url).Msg("Inference")
-	return requestEndpoint(url)
-}
-
-// parseJSONToNodeValues parses the incoming JSON string and returns a slice of NodeValue.
-func parseJSONToNodeValues(jsonStr string) ([]lib.NodeValue, error) {
-	// Define a map to hold the parsed JSON data.
-	var data map[string][]float64
-
-	// Parse the JSON string into the map.
-	err := json.Unmarshal([]byte(jsonStr), &data)
+func (a *AlloraAdapter) CalcInference(ctx context.Context, node lib.WorkerConfig, blockHeight int64) (string, error) {
+	endpoint, err := endpointRequestOf(node.Parameters, "Inference", blockHeight, node.TopicId)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	log.Debug().Str("method", endpoint.method).Str("url", endpoint.url).Msg("Inference")
+	return requestEndpoint(ctx, endpoint)
+}
 
-	// Create a slice to hold the NodeValues.
-	var nodeValues []lib.NodeValue
+// A forecast as listed in the array format
+type forecastValue struct {
+	Worker string      `json:"worker"`
+	Value  json.Number `json:"value"`
+}
 
-	// Iterate over the map to create NodeValue structs.
-	for worker, values := range data {
-		if len(values) > 0 {
-			// Only pick the first value in the list.
-			nodeValue := lib.NodeValue{
-				Worker: worker,
-				Value:  fmt.Sprintf("%f", values[0]),
+// parseJSONToNodeValues parses forecasts in either format:
+// an array of {"worker": "allo1...", "value": "0.5"} objects, with the values as decimal strings or numbers,
+// or an object of value arrays by worker, {"allo1...": [0.5]}, where only the first value of each worker is its forecast.
+// Values are kept as written, workers must be allo addresses forecasted once, and forecasts are sorted by worker.
+func parseJSONToNodeValues(jsonStr string) ([]lib.NodeValue, error) {
+	trimmed := strings.TrimSpace(jsonStr)
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+
+	nodeValues := []lib.NodeValue{}
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var forecasts []forecastValue
+		if err := decoder.Decode(&forecasts); err != nil {
+			return nil, fmt.Errorf("invalid forecast array, expected [{\"worker\": \"allo1...\", \"value\": \"0.5\"}]: %w", err)
+		}
+		for i, forecast := range forecasts {
+			if forecast.Worker == "" || forecast.Value == "" {
+				return nil, fmt.Errorf("forecast %d needs both a worker and a value", i)
 			}
-			nodeValues = append(nodeValues, nodeValue)
+			nodeValues = append(nodeValues, lib.NodeValue{Worker: forecast.Worker, Value: forecast.Value.String()})
+		}
+	case strings.HasPrefix(trimmed, "{"):
+		var forecasts map[string][]json.Number
+		if err := decoder.Decode(&forecasts); err != nil {
+			return nil, fmt.Errorf("invalid forecast object, expected {\"allo1...\": [0.5]}: %w", err)
 		}
+		for worker, values := range forecasts {
+			if len(values) > 0 {
+				// Only pick the first value in the list.
+				nodeValues = append(nodeValues, lib.NodeValue{Worker: worker, Value: values[0].String()})
+			}
+		}
+	default:
+		return nil, errors.New("unknown forecast format, expected an array of {worker, value} objects or an object of value arrays by worker")
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after forecasts")
 	}
 
+	sort.Slice(nodeValues, func(i, j int) bool { return nodeValues[i].Worker < nodeValues[j].Worker })
+	for i, nodeValue := range nodeValues {
+		if err := validateForecasterAddress(nodeValue.Worker); err != nil {
+			return nil, err
+		}
+		if i > 0 && nodeValues[i-1].Worker == nodeValue.Worker {
+			return nil, fmt.Errorf("duplicate forecast for worker %s", nodeValue.Worker)
+		}
+		if _, err := alloraMath.NewDecFromString(nodeValue.Value); err != nil {
+			return nil, fmt.Errorf("forecast for worker %s is not a decimal: %w", nodeValue.Worker, err)
+		}
+	}
 	return nodeValues, nil
 }
 
-// Expects forecast as a json array of NodeValue
-func (a *AlloraAdapter) CalcForecast(node lib.WorkerConfig, blockHeight int64) ([]lib.NodeValue, error) {
-	urlTemplate := node.Parameters["ForecastEndpoint"]
-	url := replaceExtendedPlaceholders(urlTemplate, node.Parameters, blockHeight, node.TopicId)
-	log.Info().Str("url", url).Msg("Forecasts endpoint")
+// Check the forecasted worker is a bech32 address with the allora prefix
+func validateForecasterAddress(worker string) error {
+	prefix, _, err := bech32.DecodeAndConvert(worker)
+	if err != nil {
+		return fmt.Errorf("forecasted worker %q is not a bech32 address: %w", worker, err)
+	}
+	if prefix != lib.ADDRESS_PREFIX {
+		return fmt.Errorf("forecasted worker %s is not an %s address", worker, lib.ADDRESS_PREFIX)
+	}
+	return nil
+}
+
+// Expects forecasts as a json array of {worker, value} objects, or an object of values by worker, the first value of each being its forecast
+func (a *AlloraAdapter) CalcForecast(ctx context.Context, node lib.WorkerConfig, blockHeight int64) ([]lib.NodeValue, error) {
+	endpoint, err := endpointRequestOf(node.Parameters, "Forecast", blockHeight, node.TopicId)
+	if err != nil {
+		return []lib.NodeValue{}, err
+	}
+	log.Info().Str("method", endpoint.method).Str("url", endpoint.url).Msg("Forecasts endpoint")
 
-	forecastsAsJsonString, err := requestEndpoint(url)
+	forecastsAsJsonString, err := requestEndpoint(ctx, endpoint)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get forecasts")
 		return []lib.NodeValue{}, err
@@ -121,17 +199,19 @@ func (a *AlloraAdapter) CalcForecast(node lib.WorkerConfig, blockHeight int64) (
 	nodeValues, err := parseJSONToNodeValues(forecastsAsJsonString)
 	if err != nil {
 		log.Error().Err(err).Msg("Error transforming forecasts")
-		return []lib.NodeValue{}, err
+		return []lib.NodeValue{}, &lib.InvalidValueError{Source: endpoint.url, Value: forecastsAsJsonString, Err: err}
 	}
 	return nodeValues, nil
 }
 
-func (a *AlloraAdapter) GroundTruth(node lib.ReputerConfig, blockHeight int64) (lib.Truth, error) {
-	urlTemplate := node.GroundTruthParameters["GroundTruthEndpoint"]
-	urlTemplate = strings.ReplaceAll(urlTemplate, "localhost", lib.LOCALIP)
-	url := replaceExtendedPlaceholders(urlTemplate, node.GroundTruthParameters, blockHeight, node.TopicId)
-	log.Debug().Str("url", url).Msg("Ground truth endpoint")
-	groundTruth, err := requestEndpoint(url)
+func (a *AlloraAdapter) GroundTruth(ctx context.Context, node lib.ReputerConfig, blockHeight int64) (lib.Truth, error) {
+	endpoint, err := endpointRequestOf(node.GroundTruthParameters, "GroundTruth", blockHeight, node.TopicId)
+	if err != nil {
+		return "", err
+	}
+	endpoint.url = strings.ReplaceAll(endpoint.url, "localhost", lib.LOCALIP)
+	log.Debug().Str("method", endpoint.method).Str("url", endpoint.url).Msg("Ground truth endpoint")
+	groundTruth, err := requestEndpoint(ctx, endpoint)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get ground truth")
 		return "", err
@@ -142,143 +222,149 @@ func (a *AlloraAdapter) GroundTruth(node lib.ReputerConfig, blockHeight int64) (
 		groundTruthDec, err = alloraMath.NewDecFromString(sanitizeDecString(groundTruth))
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to convert ground truth to decimal")
-			return "", err
+			return "", &lib.InvalidValueError{Source: endpoint.url, Value: groundTruth, Err: err}
 		}
 	}
-	log.Info().Str("url", url).Str("groundTruth", groundTruthDec.String()).Msg("Ground truth")
+	log.Info().Str("url", endpoint.url).Str("groundTruth", groundTruthDec.String()).Msg("Ground truth")
 	return lib.Truth(groundTruthDec.String()), nil
 }
 
-func (a *AlloraAdapter) LossFunction(node lib.ReputerConfig, groundTruth string, inferenceValue string, options map[string]string) (string, error) {
-	url := node.LossFunctionParameters.LossFunctionService
-	if url == "" {
-		return "", fmt.Errorf("no loss function endpoint provided")
-	}
-	// Use /calculate endpoint of loss-functions service
-	url = fmt.Sprintf("%s/calculate", url)
-	log.Debug().Str("url", url).Msg("Loss function endpoint")
-
-	// Prepare the request payload
-	payload := map[string]interface{}{
-		"y_true":  groundTruth,
-		"y_pred":  inferenceValue,
-		"options": options,
-	}
-
+// POST the payload as JSON to the loss function service and parse the JSON response into result.
+// Losses are pure computations, so requests are retried by the shared adapter http client.
+func postLossService(ctx context.Context, url string, payload interface{}, result interface{}) error {
 	// Convert payload to JSON
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal payload: %w", err)
+		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
 	// Create a new POST request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := lib.AdapterHttp().Do(req, true)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check the response status
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("received non-OK HTTP status %d", resp.StatusCode)
+		return &lib.HttpStatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Read and parse the response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return &lib.InvalidValueError{Source: url, Value: string(body), Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+	return nil
+}
+
+func (a *AlloraAdapter) LossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, groundTruth string, inferenceValue string, options map[string]string) (string, error) {
+	url := node.LossFunctionParameters.LossFunctionService
+	if url == "" {
+		return "", fmt.Errorf("no loss function endpoint provided")
+	}
+	// Use /calculate endpoint of loss-functions service
+	url = fmt.Sprintf("%s/calculate", url)
+	log.Debug().Str("url", url).Msg("Loss function endpoint")
+
+	// Prepare the request payload
+	payload := map[string]interface{}{
+		"y_true":  groundTruth,
+		"y_pred":  inferenceValue,
+		"options": options,
 	}
 	var result struct {
 		Loss string `json:"loss"`
 	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	if err := postLossService(ctx, url, payload, &result); err != nil {
+		return "", err
 	}
 
 	log.Debug().Str("url", url).Str("Loss", result.Loss).Msg("Calculated loss value from external endpoint")
 	return result.Loss, nil
 }
 
-func (a *AlloraAdapter) IsLossFunctionNeverNegative(node lib.ReputerConfig, options map[string]string) (bool, error) {
+// Computes the losses of all values in one request to the /calculate_batch endpoint of the loss function service.
+// Services without the endpoint are remembered, so they are only asked once.
+func (a *AlloraAdapter) BatchLossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, groundTruth string, inferenceValues []string, options map[string]string) ([]string, error) {
 	url := node.LossFunctionParameters.LossFunctionService
 	if url == "" {
-		return false, fmt.Errorf("no loss function endpoint provided")
+		return nil, fmt.Errorf("no loss function endpoint provided")
 	}
-	// Use /is_never_negative endpoint of loss-functions service
-	url = fmt.Sprintf("%s/is_never_negative", url)
-	log.Debug().Str("url", url).Msg("Checking if loss function is never negative")
+	// Use /calculate_batch endpoint of loss-functions service
+	url = fmt.Sprintf("%s/calculate_batch", url)
+	if _, unsupported := a.batchUnsupported.Load(url); unsupported {
+		return nil, lib.ErrBatchLossUnsupported
+	}
+	log.Debug().Str("url", url).Int("values", len(inferenceValues)).Msg("Batch loss function endpoint")
 
 	// Prepare the request payload
 	payload := map[string]interface{}{
+		"y_true":  groundTruth,
+		"y_pred":  inferenceValues,
 		"options": options,
 	}
-
-	// Convert payload to JSON
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return false, fmt.Errorf("failed to marshal payload: %w", err)
+	var result struct {
+		Losses []string `json:"losses"`
 	}
-
-	// Create a new POST request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
+	err := postLossService(ctx, url, payload, &result)
+	var statusErr *lib.HttpStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			log.Info().Str("url", url).Int("status", statusErr.StatusCode).Msg("Loss function service does not support batches")
+			a.batchUnsupported.Store(url, true)
+			return nil, lib.ErrBatchLossUnsupported
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	// Check the response status
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("received non-OK HTTP status %d", resp.StatusCode)
+	if len(result.Losses) != len(inferenceValues) {
+		return nil, &lib.InvalidValueError{Source: url, Value: strings.Join(result.Losses, ","), Err: fmt.Errorf("received %d losses for %d values", len(result.Losses), len(inferenceValues))}
 	}
 
-	// Read and parse the response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return false, fmt.Errorf("failed to read response body: %w", err)
+	log.Debug().Str("url", url).Int("losses", len(result.Losses)).Msg("Calculated batch of loss values from external endpoint")
+	return result.Losses, nil
+}
+
+func (a *AlloraAdapter) IsLossFunctionNeverNegative(ctx context.Context, node lib.ReputerConfig, blockHeight int64, options map[string]string) (bool, error) {
+	url := node.LossFunctionParameters.LossFunctionService
+	if url == "" {
+		return false, fmt.Errorf("no loss function endpoint provided")
 	}
+	// Use /is_never_negative endpoint of loss-functions service
+	url = fmt.Sprintf("%s/is_never_negative", url)
+	log.Debug().Str("url", url).Msg("Checking if loss function is never negative")
 
+	// Prepare the request payload
+	payload := map[string]interface{}{
+		"options": options,
+	}
 	var result struct {
 		IsNeverNegative bool `json:"is_never_negative"`
 	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return false, fmt.Errorf("failed to parse response: %w", err)
+	if err := postLossService(ctx, url, payload, &result); err != nil {
+		return false, err
 	}
 
 	log.Info().Str("url", url).Interface("options", options).Bool("IsNeverNegative", result.IsNeverNegative).Msg("Checked if loss function is never negative")
 	return result.IsNeverNegative, nil
 }
 
-func (a *AlloraAdapter) CanInfer() bool {
-	return true
-}
-
-func (a *AlloraAdapter) CanForecast() bool {
-	return true
-}
-
-func (a *AlloraAdapter) CanSourceGroundTruthAndComputeLoss() bool {
-	return true
-}
-
 func NewAlloraAdapter() *AlloraAdapter {
 	return &AlloraAdapter{
-		name: "api-worker-reputer",
+		name: adapterName,
 	}
 }
 
@@ -0,0 +1,165 @@
+package api_worker_reputer
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testInferer      = "allo1qgpqyqszqgpqyqszqgpqyqszqgpqyqszdyac3n"
+	testOtherInferer = "allo1qyqszqgpqyqszqgpqyqszqgpqyqszqgpuqma69"
+)
+
+func TestParseJSONToNodeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected []lib.NodeValue
+		err      string
+	}{
+		{
+			name:     "array of objects, sorted by worker",
+			json:     `[{"worker": "` + testOtherInferer + `", "value": "-0.123456789012345678"}, {"worker": "` + testInferer + `", "value": 2}]`,
+			expected: []lib.NodeValue{{Worker: testInferer, Value: "2"}, {Worker: testOtherInferer, Value: "-0.123456789012345678"}},
+		},
+		{
+			name:     "object of value arrays, keeping the first value as written",
+			json:     `{"` + testOtherInferer + `": [3.14159265358979, 1], "` + testInferer + `": ["0.5"], "` + testInferer[:10] + `": []}`,
+			expected: []lib.NodeValue{{Worker: testInferer, Value: "0.5"}, {Worker: testOtherInferer, Value: "3.14159265358979"}},
+		},
+		{name: "no forecasts", json: ` [] `, expected: []lib.NodeValue{}},
+		{name: "unknown format", json: `"0.5"`, err: "unknown forecast format"},
+		{name: "value that is not a number", json: `[{"worker": "` + testInferer + `", "value": "high"}]`, err: "invalid forecast array"},
+		{name: "value that is not an array", json: `{"` + testInferer + `": 0.5}`, err: "invalid forecast object"},
+		{name: "missing value", json: `[{"worker": "` + testInferer + `"}]`, err: "forecast 0 needs both a worker and a value"},
+		{name: "duplicate worker", json: `[{"worker": "` + testInferer + `", "value": "1"}, {"worker": "` + testInferer + `", "value": "2"}]`, err: "duplicate forecast for worker " + testInferer},
+		{name: "not an address", json: `{"allo1inferer": [0.5]}`, err: `forecasted worker "allo1inferer" is not a bech32 address`},
+		{name: "address of another chain", json: `[{"worker": "cosmos1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqnrql8a", "value": "1"}]`, err: "is not an allo address"},
+		{name: "trailing data", json: `[] []`, err: "unexpected data after forecasts"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			forecasts, err := parseJSONToNodeValues(test.json)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, forecasts)
+		})
+	}
+}
+
+func TestSelectJSONPath(t *testing.T) {
+	document := []byte(`{"data": {"predictions": [{"value": 3012.50}, {"value": "2999.1"}], "a.b": true, "empty": null}}`)
+	tests := []struct {
+		path     string
+		expected string
+		err      string
+	}{
+		{path: "data.predictions[0].value", expected: "3012.50"},
+		{path: "$.data.predictions.1.value", expected: "2999.1"},
+		{path: "data.predictions[-1].value", expected: "2999.1"},
+		{path: `data.a\.b`, expected: "true"},
+		{path: "data.empty", expected: "null"},
+		{path: "data.predictions[1]", expected: `{"value":"2999.1"}`},
+		{path: "", expected: `{"data":{"a.b":true,"empty":null,"predictions":[{"value":3012.50},{"value":"2999.1"}]}}`},
+		{path: "data.missing", err: "no value at data.missing"},
+		{path: "data.predictions[2]", err: "array has 2 elements"},
+		{path: "data.predictions.first", err: `indexes an array with "first"`},
+		{path: "data.predictions[0].value.x", err: "not an object or array"},
+		{path: "data..predictions", err: "empty key"},
+		{path: "data.predictions[x]", err: "not an integer"},
+		{path: "data.predictions[0", err: "unclosed ["},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			value, err := selectJSONPath(document, test.path)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestAdapterRequestsConfiguredEndpoints(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-key\n"), 0o600))
+	t.Setenv("TEST_MODEL_TOKEN", "env-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/inference":
+			if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer env-token" || r.Header.Get("X-Model") != "ETH-7" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"request": ` + string(body) + `, "result": {"inference": 3012.5}}`))
+		case "/forecast":
+			if r.Header.Get("Api-Key") != "file-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"forecasts": {"` + testInferer + `": [0.5]}}`))
+		case "/truth":
+			_, _ = w.Write([]byte(`[{"price": "1,234.5"}]`))
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewAlloraAdapter()
+	ctx := context.Background()
+	worker := lib.WorkerConfig{TopicId: 7, Parameters: map[string]string{
+		"Token":                 "ETH",
+		"InferenceEndpoint":     server.URL + "/inference",
+		"InferenceHeaders":      `{"X-Model": "{Token}-{TopicId}"}`,
+		"InferenceBody":         `{"token": "{Token}", "block": {BlockHeight}}`,
+		"InferenceBearerToken":  "{env:TEST_MODEL_TOKEN}",
+		"InferenceResultPath":   "result.inference",
+		"ForecastEndpoint":      server.URL + "/forecast",
+		"ForecastApiKey":        "{file:" + secretFile + "}",
+		"ForecastApiKeyHeader":  "Api-Key",
+		"ForecastResultPath":    "forecasts",
+		"GroundTruthEndpoint":   server.URL + "/truth",
+		"GroundTruthResultPath": "[0].price",
+	}}
+
+	inference, err := adapter.CalcInference(ctx, worker, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "3012.5", inference)
+
+	// The templated body was sent along
+	worker.Parameters["InferenceResultPath"] = "request.block"
+	block, err := adapter.CalcInference(ctx, worker, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "100", block)
+
+	forecasts, err := adapter.CalcForecast(ctx, worker, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []lib.NodeValue{{Worker: testInferer, Value: "0.5"}}, forecasts)
+
+	truth, err := adapter.GroundTruth(ctx, lib.ReputerConfig{GroundTruthParameters: worker.Parameters}, 100)
+	require.NoError(t, err)
+	assert.Equal(t, lib.Truth("1234.5"), truth)
+
+	worker.Parameters["InferenceBearerToken"] = "{env:TEST_MISSING_TOKEN}"
+	_, err = adapter.CalcInference(ctx, worker, 100)
+	assert.ErrorContains(t, err, "environment variable TEST_MISSING_TOKEN is not set")
+
+	worker.Parameters["InferenceMethod"] = "DELETE"
+	_, err = adapter.CalcInference(ctx, worker, 100)
+	assert.ErrorContains(t, err, "unsupported InferenceMethod DELETE")
+}
@@ -0,0 +1,129 @@
+package api_worker_reputer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Select the value at the path of the JSON document, in the style of gjson/JSONPath:
+// keys separated by dots, with array indexes either as `[n]` or as `.n`, e.g. `data.predictions[0].value`.
+// An optional leading `$` or `$.` is ignored, dots in keys are escaped as `\.`, and an empty path selects the whole document.
+// Strings are returned unquoted, numbers as written, and objects, arrays, booleans and null as JSON.
+func selectJSONPath(document []byte, path string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("failed to parse response as JSON: %w", err)
+	}
+
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	for i, segment := range segments {
+		at := strings.Join(segments[:i+1], ".")
+		switch node := value.(type) {
+		case map[string]interface{}:
+			child, ok := node[segment]
+			if !ok {
+				return "", fmt.Errorf("no value at %s", at)
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return "", fmt.Errorf("%s indexes an array with %q", at, segment)
+			}
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return "", fmt.Errorf("no value at %s: array has %d elements", at, len(node))
+			}
+			value = node[index]
+		default:
+			return "", fmt.Errorf("no value at %s: not an object or array", at)
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	default:
+		selected, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal selected value: %w", err)
+		}
+		return string(selected), nil
+	}
+}
+
+// Split the path into its keys and indexes
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	segments := []string{}
+	var segment strings.Builder
+	// Whether the segment being read follows a closing bracket, so may be empty
+	afterIndex := false
+	flush := func() error {
+		if segment.Len() == 0 && !afterIndex {
+			return fmt.Errorf("invalid json path %q: empty key", path)
+		}
+		if segment.Len() > 0 {
+			segments = append(segments, segment.String())
+		}
+		segment.Reset()
+		afterIndex = false
+		return nil
+	}
+	if path == "" {
+		return segments, nil
+	}
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+			}
+			segment.WriteByte(path[i])
+		case '.':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unclosed [", path)
+			}
+			if segment.Len() > 0 {
+				segments = append(segments, segment.String())
+				segment.Reset()
+			} else if i > 0 && path[i-1] == '.' {
+				return nil, fmt.Errorf("invalid json path %q: empty key", path)
+			}
+			index := path[i+1 : i+end]
+			if _, err := strconv.Atoi(index); err != nil {
+				return nil, fmt.Errorf("invalid json path %q: index %q is not an integer", path, index)
+			}
+			segments = append(segments, index)
+			i += end
+			afterIndex = true
+		default:
+			if afterIndex {
+				return nil, fmt.Errorf("invalid json path %q: expected . or [ after ]", path)
+			}
+			segment.WriteByte(c)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
@@ -0,0 +1,456 @@
+package exec_worker_reputer
+
+import (
+	"allora_offchain_node/lib"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const adapterName = "exec-worker-reputer"
+
+const DEFAULT_TIMEOUT_SECONDS = 30           // seconds a command may run, or a persistent process may take to answer
+const DEFAULT_MAX_OUTPUT_BYTES = 1024 * 1024 // bytes of output read from a command at most
+const MAX_STDERR_BYTES = 4096                // bytes of stderr kept to report failed commands
+const EXEC_WAIT_DELAY = time.Second          // time the output of a killed command is still read for
+
+// Methods of requests, telling commands what to compute
+const (
+	MethodInference       = "inference"
+	MethodForecast        = "forecast"
+	MethodGroundTruth     = "groundTruth"
+	MethodLoss            = "loss"
+	MethodIsNeverNegative = "isNeverNegative"
+)
+
+func init() {
+	roles := []lib.AdapterCapability{
+		lib.AdapterCapabilityInference,
+		lib.AdapterCapabilityForecast,
+		lib.AdapterCapabilityGroundTruth,
+		lib.AdapterCapabilityLossFunction,
+	}
+	lib.RegisterAdapter(lib.AdapterRegistration{
+		Name: adapterName,
+		New:  func() lib.Adapter { return NewAlloraAdapter() },
+		Parameters: []lib.AdapterParameter{
+			{Name: "InferenceCommand", Description: "command computing inferences, with placeholders", Required: true, Capabilities: roles[0:1]},
+			{Name: "ForecastCommand", Description: "command computing forecasts, with placeholders", Required: true, Capabilities: roles[1:2]},
+			{Name: "GroundTruthCommand", Description: "command sourcing the ground truth, with placeholders", Required: true, Capabilities: roles[2:3]},
+			{Name: "LossFunctionCommand", Description: "command computing losses, with placeholders", Required: true, Capabilities: roles[3:4]},
+			{Name: "ExecTimeoutSeconds", Description: "seconds a command may take, 30 by default", Capabilities: roles},
+			{Name: "ExecMaxOutputBytes", Description: "bytes of output read at most, 1 MiB by default", Capabilities: roles},
+			{Name: "ExecPersistent", Description: "true to keep one process running, speaking line-delimited JSON", Capabilities: roles},
+		},
+	})
+}
+
+// Runs a local command per request, passing the request as JSON on stdin and reading the response from stdout.
+// With ExecPersistent, a single long-lived process per command answers one JSON request line with one JSON response line instead.
+type AlloraAdapter struct {
+	name string
+
+	mu        sync.Mutex
+	processes map[string]*process // persistent processes, by command line
+}
+
+func (a *AlloraAdapter) Name() string {
+	return a.name
+}
+
+// Request passed to commands
+type execRequest struct {
+	Method      string            `json:"method"`
+	TopicId     uint64            `json:"topicId"`
+	BlockHeight int64             `json:"blockHeight,omitempty"`
+	Parameters  map[string]string `json:"parameters,omitempty"`
+	GroundTruth string            `json:"groundTruth,omitempty"`
+	Value       string            `json:"value,omitempty"`
+}
+
+// Response read from commands. Commands run per request may also print the bare value instead.
+type execResponse struct {
+	Value           json.Number     `json:"value,omitempty"` // inference, ground truth or loss
+	Forecasts       []lib.NodeValue `json:"forecasts,omitempty"`
+	IsNeverNegative bool            `json:"isNeverNegative,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// Settings of a command, read from the parameters of its role
+type commandSettings struct {
+	args           []string
+	timeout        time.Duration
+	maxOutputBytes int
+	persistent     bool
+}
+
+// Settings of the command configured under the key of the parameters. Placeholders of the arguments are expanded,
+// except {BlockHeight} for persistent processes, which serve every block height.
+func commandSettingsOf(params map[string]string, key string, blockHeight int64, topicId uint64) (commandSettings, error) {
+	template := strings.Fields(params[key])
+	if len(template) == 0 {
+		return commandSettings{}, fmt.Errorf("no %s provided", key)
+	}
+	settings := commandSettings{
+		timeout:        DEFAULT_TIMEOUT_SECONDS * time.Second,
+		maxOutputBytes: DEFAULT_MAX_OUTPUT_BYTES,
+		persistent:     params["ExecPersistent"] == "true",
+	}
+	if seconds, err := strconv.ParseFloat(params["ExecTimeoutSeconds"], 64); err == nil && seconds > 0 {
+		settings.timeout = time.Duration(seconds * float64(time.Second))
+	}
+	if maxBytes, err := strconv.Atoi(params["ExecMaxOutputBytes"]); err == nil && maxBytes > 0 {
+		settings.maxOutputBytes = maxBytes
+	}
+	for _, arg := range template {
+		if settings.persistent {
+			arg = lib.ReplacePlaceholders(arg, map[string]string{"TopicId": strconv.FormatUint(topicId, 10)})
+			arg = lib.ReplacePlaceholders(arg, params)
+		} else {
+			arg = lib.ReplaceExtendedPlaceholders(arg, params, blockHeight, topicId)
+		}
+		settings.args = append(settings.args, arg)
+	}
+	return settings, nil
+}
+
+// Run the command on the request and parse its response. Bare values are accepted for scalar methods
+// of commands run per request.
+func (a *AlloraAdapter) call(ctx context.Context, settings commandSettings, req execRequest) (execResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return execResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, settings.timeout)
+	defer cancel()
+
+	var output []byte
+	if settings.persistent {
+		output, err = a.process(settings).call(ctx, input)
+	} else {
+		output, err = runCommand(ctx, settings, input)
+	}
+	if err != nil {
+		return execResponse{}, err
+	}
+
+	output = bytes.TrimSpace(output)
+	var res execResponse
+	if len(output) > 0 && output[0] == '{' {
+		if err := json.Unmarshal(output, &res); err != nil {
+			return execResponse{}, fmt.Errorf("failed to parse response of %s: %w", settings.args[0], err)
+		}
+		if res.Error != "" {
+			return execResponse{}, fmt.Errorf("%s failed: %s", settings.args[0], res.Error)
+		}
+		return res, nil
+	}
+	if settings.persistent {
+		return execResponse{}, fmt.Errorf("response of %s is not a JSON object", settings.args[0])
+	}
+	switch req.Method {
+	case MethodInference, MethodGroundTruth, MethodLoss:
+		err = json.Unmarshal(output, &res.Value)
+	case MethodIsNeverNegative:
+		res.IsNeverNegative, err = strconv.ParseBool(string(output))
+	case MethodForecast:
+		err = json.Unmarshal(output, &res.Forecasts)
+	}
+	if err != nil {
+		return execResponse{}, fmt.Errorf("failed to parse response of %s: %w", settings.args[0], err)
+	}
+	return res, nil
+}
+
+// Buffer keeping the first bytes written to it, discarding the rest.
+// Not embedding bytes.Buffer keeps io.Copy from bypassing Write through ReadFrom.
+type cappedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+var errOutputTooLong = errors.New("output too long")
+
+// Buffer holding at most max bytes, calling overflow and failing writes past them
+type limitedBuffer struct {
+	buf        bytes.Buffer
+	max        int
+	overflow   func()
+	overflowed bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		b.overflowed = true
+		b.overflow()
+		return 0, errOutputTooLong
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// Commands that could not be run to an answer are reported as unavailable sources, so the nonce is retried
+// on the next loop. Answers carrying an error are not.
+func unavailable(command string, err error) error {
+	return &lib.SourceUnavailableError{Source: command, Err: err}
+}
+
+// Run the command once with the input on stdin, returning its stdout. Killed once ctx is done
+// or its output exceeds the limit.
+func runCommand(ctx context.Context, settings commandSettings, input []byte) ([]byte, error) {
+	runCtx, kill := context.WithCancel(ctx)
+	defer kill()
+	cmd := exec.CommandContext(runCtx, settings.args[0], settings.args[1:]...)
+	// Children of the command left holding its output do not keep it waited for
+	cmd.WaitDelay = EXEC_WAIT_DELAY
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &limitedBuffer{max: settings.maxOutputBytes, overflow: kill}
+	cmd.Stdout = stdout
+	stderr := &cappedBuffer{max: MAX_STDERR_BYTES}
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	switch {
+	case stdout.overflowed:
+		return nil, fmt.Errorf("output of %s exceeds %d bytes", settings.args[0], settings.maxOutputBytes)
+	case err != nil && ctx.Err() != nil:
+		return nil, unavailable(settings.args[0], fmt.Errorf("did not finish in time: %w", ctx.Err()))
+	case err != nil:
+		return nil, unavailable(settings.args[0], fmt.Errorf("failed: %w: %s", err, strings.TrimSpace(stderr.String())))
+	}
+	return stdout.Bytes(), nil
+}
+
+// Persistent process of the command line, started on first use
+func (a *AlloraAdapter) process(settings commandSettings) *process {
+	key := strings.Join(settings.args, " ")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, ok := a.processes[key]
+	if !ok {
+		p = &process{args: settings.args, maxOutputBytes: settings.maxOutputBytes}
+		a.processes[key] = p
+	}
+	return p
+}
+
+// Stop the persistent processes. Calls made afterwards start new ones.
+func (a *AlloraAdapter) Close() error {
+	a.mu.Lock()
+	processes := a.processes
+	a.processes = map[string]*process{}
+	a.mu.Unlock()
+	for _, p := range processes {
+		// Waits for the request in flight, if any, which is bounded by its timeout
+		p.mu.Lock()
+		p.stop()
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// A long-lived process answering one request line at a time. (Re)started whenever it is not running,
+// and killed when it does not answer in time or answers with too long a line, as it is then out of step.
+// It should exit once its stdin is closed.
+type process struct {
+	args           []string
+	maxOutputBytes int
+
+	mu     sync.Mutex // one request at a time
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout chan []byte // lines of stdout
+	exited chan struct{}
+	stderr *cappedBuffer
+}
+
+func (p *process) start() error {
+	cmd := exec.Command(p.args[0], p.args[1:]...)
+	cmd.WaitDelay = EXEC_WAIT_DELAY
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	p.stderr = &cappedBuffer{max: MAX_STDERR_BYTES}
+	cmd.Stderr = p.stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	log.Info().Strs("args", p.args).Int("pid", cmd.Process.Pid).Msg("Started persistent process")
+
+	p.cmd, p.stdin = cmd, stdin
+	p.stdout, p.exited = make(chan []byte, 1), make(chan struct{})
+	stdout, exited := p.stdout, p.exited
+	go func() {
+		defer close(exited)
+		// Lines longer than the limit end the process, as the rest of the line would be read as the next response
+		reader := bufio.NewReaderSize(stdoutPipe, p.maxOutputBytes+1)
+		for {
+			line, err := reader.ReadSlice('\n')
+			if errors.Is(err, bufio.ErrBufferFull) {
+				log.Error().Strs("args", p.args).Int("maxOutputBytes", p.maxOutputBytes).Msg("Persistent process answered with too long a line, killing it")
+			}
+			if err != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				return
+			}
+			select {
+			case stdout <- append([]byte{}, line...):
+			default:
+				log.Error().Strs("args", p.args).Msg("Persistent process wrote a line it was not asked for, killing it")
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (p *process) stop() {
+	if p.cmd != nil {
+		_ = p.stdin.Close()
+		_ = p.cmd.Process.Kill()
+		p.cmd = nil
+	}
+}
+
+func (p *process) running() bool {
+	if p.cmd == nil {
+		return false
+	}
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// Write the input as a line and read the response line, until ctx is done
+func (p *process) call(ctx context.Context, input []byte) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running() {
+		p.stop()
+		if err := p.start(); err != nil {
+			return nil, unavailable(p.args[0], fmt.Errorf("failed to start: %w", err))
+		}
+	}
+	if _, err := p.stdin.Write(append(input, '\n')); err != nil {
+		p.stop()
+		return nil, unavailable(p.args[0], fmt.Errorf("failed to write request: %w", err))
+	}
+	select {
+	case line := <-p.stdout:
+		return line, nil
+	case <-p.exited:
+		stderr := strings.TrimSpace(p.stderr.String())
+		p.stop()
+		return nil, unavailable(p.args[0], fmt.Errorf("exited without answering: %s", stderr))
+	case <-ctx.Done():
+		p.stop()
+		return nil, unavailable(p.args[0], fmt.Errorf("did not answer in time: %w", ctx.Err()))
+	}
+}
+
+func (a *AlloraAdapter) CalcInference(ctx context.Context, node lib.WorkerConfig, blockHeight int64) (string, error) {
+	settings, err := commandSettingsOf(node.Parameters, "InferenceCommand", blockHeight, node.TopicId)
+	if err != nil {
+		return "", err
+	}
+	res, err := a.call(ctx, settings, execRequest{Method: MethodInference, TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: node.Parameters})
+	if err != nil {
+		return "", err
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Str("inference", res.Value.String()).Msg("Got inference from command")
+	return res.Value.String(), nil
+}
+
+func (a *AlloraAdapter) CalcForecast(ctx context.Context, node lib.WorkerConfig, blockHeight int64) ([]lib.NodeValue, error) {
+	settings, err := commandSettingsOf(node.Parameters, "ForecastCommand", blockHeight, node.TopicId)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.call(ctx, settings, execRequest{Method: MethodForecast, TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: node.Parameters})
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Int("forecasts", len(res.Forecasts)).Msg("Got forecasts from command")
+	return res.Forecasts, nil
+}
+
+func (a *AlloraAdapter) GroundTruth(ctx context.Context, node lib.ReputerConfig, blockHeight int64) (lib.Truth, error) {
+	settings, err := commandSettingsOf(node.GroundTruthParameters, "GroundTruthCommand", blockHeight, node.TopicId)
+	if err != nil {
+		return "", err
+	}
+	res, err := a.call(ctx, settings, execRequest{Method: MethodGroundTruth, TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: node.GroundTruthParameters})
+	if err != nil {
+		return "", err
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Str("groundTruth", res.Value.String()).Msg("Got ground truth from command")
+	return res.Value.String(), nil
+}
+
+func (a *AlloraAdapter) LossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, groundTruth string, inferenceValue string, options map[string]string) (string, error) {
+	settings, err := commandSettingsOf(options, "LossFunctionCommand", blockHeight, node.TopicId)
+	if err != nil {
+		return "", err
+	}
+	res, err := a.call(ctx, settings, execRequest{Method: MethodLoss, TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: options, GroundTruth: groundTruth, Value: inferenceValue})
+	if err != nil {
+		return "", err
+	}
+	return res.Value.String(), nil
+}
+
+func (a *AlloraAdapter) IsLossFunctionNeverNegative(ctx context.Context, node lib.ReputerConfig, blockHeight int64, options map[string]string) (bool, error) {
+	settings, err := commandSettingsOf(options, "LossFunctionCommand", blockHeight, node.TopicId)
+	if err != nil {
+		return false, err
+	}
+	res, err := a.call(ctx, settings, execRequest{Method: MethodIsNeverNegative, TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: options})
+	if err != nil {
+		return false, err
+	}
+	log.Info().Interface("options", options).Bool("IsNeverNegative", res.IsNeverNegative).Msg("Checked if loss function is never negative")
+	return res.IsNeverNegative, nil
+}
+
+func NewAlloraAdapter() *AlloraAdapter {
+	return &AlloraAdapter{
+		name:      adapterName,
+		processes: map[string]*process{},
+	}
+}
@@ -0,0 +1,146 @@
+package exec_worker_reputer
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Write the shell script to the directory, returning a command running it
+func writeScript(t *testing.T, dir string, name string, script string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o600))
+	return "sh " + path
+}
+
+func TestExecAdapterRunsCommands(t *testing.T) {
+	dir := t.TempDir()
+	adapter := NewAlloraAdapter()
+	ctx := context.Background()
+
+	worker := lib.WorkerConfig{TopicId: 7, Parameters: map[string]string{
+		"Token":            "ETH",
+		"InferenceCommand": writeScript(t, dir, "inference.sh", `echo "$1"`) + " {Token}-{TopicId}-{BlockHeight}",
+		"ForecastCommand":  writeScript(t, dir, "forecast.sh", `echo '[{"worker":"allo1inferer","value":"0.5"}]'`),
+	}}
+	_, err := adapter.CalcInference(ctx, worker, 100)
+	assert.ErrorContains(t, err, "failed to parse response", "inference must be a decimal")
+
+	worker.Parameters["InferenceCommand"] = writeScript(t, dir, "inference.sh", `echo "$1.5"`) + " {BlockHeight}"
+	inference, err := adapter.CalcInference(ctx, worker, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "100.5", inference)
+
+	forecasts, err := adapter.CalcForecast(ctx, worker, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []lib.NodeValue{{Worker: "allo1inferer", Value: "0.5"}}, forecasts)
+
+	// The request is passed as JSON on stdin: echoing it back answers with the value it carries
+	reputer := lib.ReputerConfig{TopicId: 7}
+	options := map[string]string{"LossFunctionCommand": "cat"}
+	loss, err := adapter.LossFunction(ctx, reputer, 100, "10", "9.5", options)
+	require.NoError(t, err)
+	assert.Equal(t, "9.5", loss)
+
+	// The block height of the nonce reaches loss commands, as argument and in the request
+	options["LossFunctionCommand"] = writeScript(t, dir, "loss.sh", `grep -q '"blockHeight":100,' && echo "$1"`) + " {BlockHeight}.25"
+	loss, err = adapter.LossFunction(ctx, reputer, 100, "10", "9.5", options)
+	require.NoError(t, err)
+	assert.Equal(t, "100.25", loss)
+
+	options["LossFunctionCommand"] = writeScript(t, dir, "never-negative.sh", `grep -q '"blockHeight":100,' && echo "$1"`) + " true"
+	neverNegative, err := adapter.IsLossFunctionNeverNegative(ctx, reputer, 100, options)
+	require.NoError(t, err)
+	assert.True(t, neverNegative)
+
+	options["LossFunctionCommand"] = writeScript(t, dir, "loss.sh", `echo "no loss for you" >&2; exit 3`)
+	_, err = adapter.LossFunction(ctx, reputer, 100, "10", "9.5", options)
+	assert.ErrorContains(t, err, "no loss for you")
+	assert.True(t, lib.IsSourceUnavailable(err))
+
+	reputer.GroundTruthParameters = map[string]string{"GroundTruthCommand": writeScript(t, dir, "truth.sh", `echo '{"error":"source down"}'`)}
+	_, err = adapter.GroundTruth(ctx, reputer, 100)
+	assert.ErrorContains(t, err, "source down")
+	assert.False(t, lib.IsSourceUnavailable(err), "the command answered")
+}
+
+func TestExecAdapterEnforcesTimeoutsAndOutputLimits(t *testing.T) {
+	dir := t.TempDir()
+	adapter := NewAlloraAdapter()
+	worker := lib.WorkerConfig{Parameters: map[string]string{
+		"InferenceCommand":   writeScript(t, dir, "slow.sh", `sleep 5; echo 1`),
+		"ExecTimeoutSeconds": "0.1",
+	}}
+	start := time.Now()
+	_, err := adapter.CalcInference(context.Background(), worker, 1)
+	assert.ErrorContains(t, err, "did not finish in time")
+	assert.True(t, lib.IsSourceUnavailable(err))
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	worker.Parameters = map[string]string{
+		"InferenceCommand":   writeScript(t, dir, "verbose.sh", `head -c 100000 /dev/zero | tr '\0' 1`),
+		"ExecMaxOutputBytes": "100",
+	}
+	_, err = adapter.CalcInference(context.Background(), worker, 1)
+	assert.ErrorContains(t, err, "exceeds 100 bytes")
+}
+
+func TestExecAdapterKeepsPersistentProcesses(t *testing.T) {
+	dir := t.TempDir()
+	adapter := NewAlloraAdapter()
+	// Answers each request line with the number of requests it got
+	script := `n=0
+while read -r line; do
+  n=$((n+1))
+  case "$line" in
+    *'"blockHeight":3'*) sleep 5 ;;
+  esac
+  echo "{\"value\":\"$n\"}"
+done`
+	worker := lib.WorkerConfig{Parameters: map[string]string{
+		"InferenceCommand":   writeScript(t, dir, "server.sh", script),
+		"ExecPersistent":     "true",
+		"ExecTimeoutSeconds": "1",
+	}}
+
+	for _, expected := range []string{"1", "2"} {
+		inference, err := adapter.CalcInference(context.Background(), worker, 1)
+		require.NoError(t, err)
+		assert.Equal(t, expected, inference)
+	}
+
+	// A process that does not answer in time is replaced
+	_, err := adapter.CalcInference(context.Background(), worker, 3)
+	assert.ErrorContains(t, err, "did not answer in time")
+	assert.True(t, lib.IsSourceUnavailable(err))
+	inference, err := adapter.CalcInference(context.Background(), worker, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "1", inference)
+
+	// Closing the adapter stops the processes
+	var exited []chan struct{}
+	for _, p := range adapter.processes {
+		exited = append(exited, p.exited)
+	}
+	require.NoError(t, adapter.Close())
+	assert.Empty(t, adapter.processes)
+	for _, e := range exited {
+		select {
+		case <-e:
+		case <-time.After(time.Second):
+			t.Fatal("persistent process still running after close")
+		}
+	}
+
+	// A command that cannot be started is unavailable
+	missing := lib.WorkerConfig{Parameters: map[string]string{"InferenceCommand": filepath.Join(dir, "missing"), "ExecPersistent": "true"}}
+	_, err = adapter.CalcInference(context.Background(), missing, 1)
+	assert.ErrorContains(t, err, "failed to start")
+	assert.True(t, lib.IsSourceUnavailable(err))
+}
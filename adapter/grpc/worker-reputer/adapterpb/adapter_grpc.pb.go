@@ -0,0 +1,289 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: adapter.proto
+
+package adapterpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AdapterService_CalcInference_FullMethodName   = "/allora.offchain.adapter.v1.AdapterService/CalcInference"
+	AdapterService_CalcForecast_FullMethodName    = "/allora.offchain.adapter.v1.AdapterService/CalcForecast"
+	AdapterService_GroundTruth_FullMethodName     = "/allora.offchain.adapter.v1.AdapterService/GroundTruth"
+	AdapterService_LossFunction_FullMethodName    = "/allora.offchain.adapter.v1.AdapterService/LossFunction"
+	AdapterService_IsNeverNegative_FullMethodName = "/allora.offchain.adapter.v1.AdapterService/IsNeverNegative"
+)
+
+// AdapterServiceClient is the client API for AdapterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Service backing the grpc-worker-reputer adapter of the Allora offchain node.
+// A server may implement only the methods of the roles it is configured for.
+type AdapterServiceClient interface {
+	// Inference of a worker for the topic at the block height
+	CalcInference(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (*InferenceResponse, error)
+	// Forecasts of a worker for the topic at the block height
+	CalcForecast(ctx context.Context, in *ForecastRequest, opts ...grpc.CallOption) (*ForecastResponse, error)
+	// Ground truth of a reputer for the topic at the block height
+	GroundTruth(ctx context.Context, in *GroundTruthRequest, opts ...grpc.CallOption) (*GroundTruthResponse, error)
+	// Loss of a value against the ground truth
+	LossFunction(ctx context.Context, in *LossRequest, opts ...grpc.CallOption) (*LossResponse, error)
+	// Whether the loss function configured by the options never returns negative losses
+	IsNeverNegative(ctx context.Context, in *IsNeverNegativeRequest, opts ...grpc.CallOption) (*IsNeverNegativeResponse, error)
+}
+
+type adapterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdapterServiceClient(cc grpc.ClientConnInterface) AdapterServiceClient {
+	return &adapterServiceClient{cc}
+}
+
+func (c *adapterServiceClient) CalcInference(ctx context.Context, in *InferenceRequest, opts ...grpc.CallOption) (*InferenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InferenceResponse)
+	err := c.cc.Invoke(ctx, AdapterService_CalcInference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterServiceClient) CalcForecast(ctx context.Context, in *ForecastRequest, opts ...grpc.CallOption) (*ForecastResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForecastResponse)
+	err := c.cc.Invoke(ctx, AdapterService_CalcForecast_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterServiceClient) GroundTruth(ctx context.Context, in *GroundTruthRequest, opts ...grpc.CallOption) (*GroundTruthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroundTruthResponse)
+	err := c.cc.Invoke(ctx, AdapterService_GroundTruth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterServiceClient) LossFunction(ctx context.Context, in *LossRequest, opts ...grpc.CallOption) (*LossResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LossResponse)
+	err := c.cc.Invoke(ctx, AdapterService_LossFunction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterServiceClient) IsNeverNegative(ctx context.Context, in *IsNeverNegativeRequest, opts ...grpc.CallOption) (*IsNeverNegativeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IsNeverNegativeResponse)
+	err := c.cc.Invoke(ctx, AdapterService_IsNeverNegative_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdapterServiceServer is the server API for AdapterService service.
+// All implementations must embed UnimplementedAdapterServiceServer
+// for forward compatibility.
+//
+// Service backing the grpc-worker-reputer adapter of the Allora offchain node.
+// A server may implement only the methods of the roles it is configured for.
+type AdapterServiceServer interface {
+	// Inference of a worker for the topic at the block height
+	CalcInference(context.Context, *InferenceRequest) (*InferenceResponse, error)
+	// Forecasts of a worker for the topic at the block height
+	CalcForecast(context.Context, *ForecastRequest) (*ForecastResponse, error)
+	// Ground truth of a reputer for the topic at the block height
+	GroundTruth(context.Context, *GroundTruthRequest) (*GroundTruthResponse, error)
+	// Loss of a value against the ground truth
+	LossFunction(context.Context, *LossRequest) (*LossResponse, error)
+	// Whether the loss function configured by the options never returns negative losses
+	IsNeverNegative(context.Context, *IsNeverNegativeRequest) (*IsNeverNegativeResponse, error)
+	mustEmbedUnimplementedAdapterServiceServer()
+}
+
+// UnimplementedAdapterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdapterServiceServer struct{}
+
+func (UnimplementedAdapterServiceServer) CalcInference(context.Context, *InferenceRequest) (*InferenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CalcInference not implemented")
+}
+func (UnimplementedAdapterServiceServer) CalcForecast(context.Context, *ForecastRequest) (*ForecastResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CalcForecast not implemented")
+}
+func (UnimplementedAdapterServiceServer) GroundTruth(context.Context, *GroundTruthRequest) (*GroundTruthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroundTruth not implemented")
+}
+func (UnimplementedAdapterServiceServer) LossFunction(context.Context, *LossRequest) (*LossResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LossFunction not implemented")
+}
+func (UnimplementedAdapterServiceServer) IsNeverNegative(context.Context, *IsNeverNegativeRequest) (*IsNeverNegativeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IsNeverNegative not implemented")
+}
+func (UnimplementedAdapterServiceServer) mustEmbedUnimplementedAdapterServiceServer() {}
+func (UnimplementedAdapterServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeAdapterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdapterServiceServer will
+// result in compilation errors.
+type UnsafeAdapterServiceServer interface {
+	mustEmbedUnimplementedAdapterServiceServer()
+}
+
+func RegisterAdapterServiceServer(s grpc.ServiceRegistrar, srv AdapterServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAdapterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdapterService_ServiceDesc, srv)
+}
+
+func _AdapterService_CalcInference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServiceServer).CalcInference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdapterService_CalcInference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServiceServer).CalcInference(ctx, req.(*InferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdapterService_CalcForecast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForecastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServiceServer).CalcForecast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdapterService_CalcForecast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServiceServer).CalcForecast(ctx, req.(*ForecastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdapterService_GroundTruth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroundTruthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServiceServer).GroundTruth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdapterService_GroundTruth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServiceServer).GroundTruth(ctx, req.(*GroundTruthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdapterService_LossFunction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LossRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServiceServer).LossFunction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdapterService_LossFunction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServiceServer).LossFunction(ctx, req.(*LossRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdapterService_IsNeverNegative_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IsNeverNegativeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServiceServer).IsNeverNegative(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdapterService_IsNeverNegative_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServiceServer).IsNeverNegative(ctx, req.(*IsNeverNegativeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdapterService_ServiceDesc is the grpc.ServiceDesc for AdapterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdapterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "allora.offchain.adapter.v1.AdapterService",
+	HandlerType: (*AdapterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CalcInference",
+			Handler:    _AdapterService_CalcInference_Handler,
+		},
+		{
+			MethodName: "CalcForecast",
+			Handler:    _AdapterService_CalcForecast_Handler,
+		},
+		{
+			MethodName: "GroundTruth",
+			Handler:    _AdapterService_GroundTruth_Handler,
+		},
+		{
+			MethodName: "LossFunction",
+			Handler:    _AdapterService_LossFunction_Handler,
+		},
+		{
+			MethodName: "IsNeverNegative",
+			Handler:    _AdapterService_IsNeverNegative_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "adapter.proto",
+}
@@ -0,0 +1,253 @@
+package grpc_worker_reputer
+
+import (
+	"allora_offchain_node/adapter/grpc/worker-reputer/adapterpb"
+	"allora_offchain_node/lib"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const adapterName = "grpc-worker-reputer"
+
+const DEFAULT_TIMEOUT_SECONDS = 10 // deadline of each call to the service
+
+func init() {
+	roles := []lib.AdapterCapability{
+		lib.AdapterCapabilityInference,
+		lib.AdapterCapabilityForecast,
+		lib.AdapterCapabilityGroundTruth,
+		lib.AdapterCapabilityLossFunction,
+	}
+	lib.RegisterAdapter(lib.AdapterRegistration{
+		Name: adapterName,
+		New:  func() lib.Adapter { return NewAlloraAdapter() },
+		Parameters: []lib.AdapterParameter{
+			{Name: "GrpcEndpoint", Description: "host:port of the adapter service", Required: true, Capabilities: roles[:3]},
+			{Name: "LossFunctionService", Description: "host:port of the adapter service computing losses", Required: true, Capabilities: roles[3:]},
+			{Name: "GrpcTls", Description: "true to connect over TLS, verified against the system roots", Capabilities: roles},
+			{Name: "GrpcCaFile", Description: "PEM file of the CA to verify the service with, implies TLS", Capabilities: roles},
+			{Name: "GrpcCertFile", Description: "PEM client certificate for mutual TLS, with GrpcKeyFile", Capabilities: roles},
+			{Name: "GrpcKeyFile", Description: "PEM client key for mutual TLS, with GrpcCertFile", Capabilities: roles},
+			{Name: "GrpcServerName", Description: "name to verify the service certificate against, the endpoint host by default", Capabilities: roles},
+			{Name: "GrpcTimeoutSeconds", Description: "deadline of each call, 10 by default", Capabilities: roles},
+		},
+	})
+}
+
+// Calls an AdapterService, as defined in proto/adapter.proto, over gRPC.
+// Connection, TLS and deadline settings are read from the parameters of the role: the worker parameters,
+// the ground truth parameters or the loss method options. All of them are sent along to the service.
+type AlloraAdapter struct {
+	name string
+	// Dial options added to those built from the parameters, e.g. to dial in-process servers
+	dialOptions []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn // by endpoint and TLS settings
+}
+
+func (a *AlloraAdapter) Name() string {
+	return a.name
+}
+
+// Connection settings read from the parameters of a role
+type connSettings struct {
+	endpoint   string
+	tls        bool
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+}
+
+func connSettingsOf(endpoint string, params map[string]string) connSettings {
+	return connSettings{
+		endpoint:   endpoint,
+		tls:        params["GrpcTls"] == "true" || params["GrpcCaFile"] != "",
+		caFile:     params["GrpcCaFile"],
+		certFile:   params["GrpcCertFile"],
+		keyFile:    params["GrpcKeyFile"],
+		serverName: params["GrpcServerName"],
+	}
+}
+
+func (s connSettings) transportCredentials() (credentials.TransportCredentials, error) {
+	if !s.tls {
+		return insecure.NewCredentials(), nil
+	}
+	config := &tls.Config{ServerName: s.serverName, MinVersion: tls.VersionTLS12}
+	if s.caFile != "" {
+		pem, err := os.ReadFile(s.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", s.caFile)
+		}
+	}
+	if s.certFile != "" || s.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// Client of the service at the endpoint, sharing one connection per endpoint and TLS settings
+func (a *AlloraAdapter) client(endpoint string, params map[string]string) (adapterpb.AdapterServiceClient, error) {
+	if endpoint == "" {
+		return nil, errors.New("no grpc endpoint provided")
+	}
+	settings := connSettingsOf(endpoint, params)
+	key := fmt.Sprintf("%+v", settings)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if conn, ok := a.conns[key]; ok {
+		return adapterpb.NewAdapterServiceClient(conn), nil
+	}
+	creds, err := settings.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(endpoint, append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, a.dialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc client for %s: %w", endpoint, err)
+	}
+	a.conns[key] = conn
+	return adapterpb.NewAdapterServiceClient(conn), nil
+}
+
+// Context of a call, due after GrpcTimeoutSeconds or the deadline of ctx, whichever comes first
+func callContext(ctx context.Context, params map[string]string) (context.Context, context.CancelFunc) {
+	timeout := DEFAULT_TIMEOUT_SECONDS * time.Second
+	if seconds, err := strconv.ParseFloat(params["GrpcTimeoutSeconds"], 64); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Error of a call to the service at the endpoint. Services that could not be reached or did not answer
+// in time are reported as a SourceUnavailableError, so the nonce is retried on the next loop.
+func callError(endpoint string, err error) error {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return &lib.SourceUnavailableError{Source: endpoint, Err: err}
+	}
+	return err
+}
+
+func (a *AlloraAdapter) CalcInference(ctx context.Context, node lib.WorkerConfig, blockHeight int64) (string, error) {
+	client, err := a.client(node.Parameters["GrpcEndpoint"], node.Parameters)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := callContext(ctx, node.Parameters)
+	defer cancel()
+	res, err := client.CalcInference(ctx, &adapterpb.InferenceRequest{TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: node.Parameters})
+	if err != nil {
+		return "", fmt.Errorf("failed to get inference: %w", callError(node.Parameters["GrpcEndpoint"], err))
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Str("inference", res.Value).Msg("Got inference over grpc")
+	return res.Value, nil
+}
+
+func (a *AlloraAdapter) CalcForecast(ctx context.Context, node lib.WorkerConfig, blockHeight int64) ([]lib.NodeValue, error) {
+	client, err := a.client(node.Parameters["GrpcEndpoint"], node.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := callContext(ctx, node.Parameters)
+	defer cancel()
+	res, err := client.CalcForecast(ctx, &adapterpb.ForecastRequest{TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: node.Parameters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecasts: %w", callError(node.Parameters["GrpcEndpoint"], err))
+	}
+	forecasts := make([]lib.NodeValue, len(res.Values))
+	for i, value := range res.Values {
+		forecasts[i] = lib.NodeValue{Worker: value.Worker, Value: value.Value}
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Int("forecasts", len(forecasts)).Msg("Got forecasts over grpc")
+	return forecasts, nil
+}
+
+func (a *AlloraAdapter) GroundTruth(ctx context.Context, node lib.ReputerConfig, blockHeight int64) (lib.Truth, error) {
+	client, err := a.client(node.GroundTruthParameters["GrpcEndpoint"], node.GroundTruthParameters)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := callContext(ctx, node.GroundTruthParameters)
+	defer cancel()
+	res, err := client.GroundTruth(ctx, &adapterpb.GroundTruthRequest{TopicId: node.TopicId, BlockHeight: blockHeight, Parameters: node.GroundTruthParameters})
+	if err != nil {
+		return "", fmt.Errorf("failed to get ground truth: %w", callError(node.GroundTruthParameters["GrpcEndpoint"], err))
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Str("groundTruth", res.Value).Msg("Got ground truth over grpc")
+	return res.Value, nil
+}
+
+func (a *AlloraAdapter) LossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, groundTruth string, inferenceValue string, options map[string]string) (string, error) {
+	client, err := a.client(node.LossFunctionParameters.LossFunctionService, options)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := callContext(ctx, options)
+	defer cancel()
+	res, err := client.LossFunction(ctx, &adapterpb.LossRequest{TopicId: node.TopicId, BlockHeight: blockHeight, GroundTruth: groundTruth, Value: inferenceValue, Options: options})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute loss: %w", callError(node.LossFunctionParameters.LossFunctionService, err))
+	}
+	log.Debug().Uint64("topicId", node.TopicId).Str("loss", res.Loss).Msg("Computed loss over grpc")
+	return res.Loss, nil
+}
+
+func (a *AlloraAdapter) IsLossFunctionNeverNegative(ctx context.Context, node lib.ReputerConfig, blockHeight int64, options map[string]string) (bool, error) {
+	client, err := a.client(node.LossFunctionParameters.LossFunctionService, options)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := callContext(ctx, options)
+	defer cancel()
+	res, err := client.IsNeverNegative(ctx, &adapterpb.IsNeverNegativeRequest{TopicId: node.TopicId, BlockHeight: blockHeight, Options: options})
+	if err != nil {
+		return false, fmt.Errorf("failed to check if loss function is never negative: %w", callError(node.LossFunctionParameters.LossFunctionService, err))
+	}
+	log.Info().Interface("options", options).Bool("IsNeverNegative", res.IsNeverNegative).Msg("Checked if loss function is never negative")
+	return res.IsNeverNegative, nil
+}
+
+// Close the connections to the services. Calls made afterwards open new ones.
+func (a *AlloraAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var errs []error
+	for key, conn := range a.conns {
+		errs = append(errs, conn.Close())
+		delete(a.conns, key)
+	}
+	return errors.Join(errs...)
+}
+
+func NewAlloraAdapter() *AlloraAdapter {
+	return &AlloraAdapter{
+		name:  adapterName,
+		conns: map[string]*grpc.ClientConn{},
+	}
+}
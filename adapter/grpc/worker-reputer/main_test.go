@@ -0,0 +1,182 @@
+package grpc_worker_reputer
+
+import (
+	"allora_offchain_node/adapter/grpc/worker-reputer/adapterpb"
+	"allora_offchain_node/lib"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testAdapterServer struct {
+	adapterpb.UnimplementedAdapterServiceServer
+	delay time.Duration
+}
+
+func (s *testAdapterServer) CalcInference(ctx context.Context, req *adapterpb.InferenceRequest) (*adapterpb.InferenceResponse, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &adapterpb.InferenceResponse{Value: req.Parameters["Token"] + "@" + time.Unix(req.BlockHeight, 0).UTC().Format("15:04:05")}, nil
+}
+
+func (s *testAdapterServer) CalcForecast(ctx context.Context, req *adapterpb.ForecastRequest) (*adapterpb.ForecastResponse, error) {
+	return &adapterpb.ForecastResponse{Values: []*adapterpb.ForecastValue{{Worker: "allo1inferer", Value: "0.5"}}}, nil
+}
+
+func (s *testAdapterServer) GroundTruth(ctx context.Context, req *adapterpb.GroundTruthRequest) (*adapterpb.GroundTruthResponse, error) {
+	return &adapterpb.GroundTruthResponse{Value: "10.5"}, nil
+}
+
+func (s *testAdapterServer) LossFunction(ctx context.Context, req *adapterpb.LossRequest) (*adapterpb.LossResponse, error) {
+	return &adapterpb.LossResponse{Loss: req.GroundTruth + "-" + req.Value + "-" + req.Options["loss_method"] + "@" + strconv.FormatInt(req.BlockHeight, 10)}, nil
+}
+
+// Adapter connected to an in-process server
+func newTestAdapter(t *testing.T, server *testAdapterServer, opts ...grpc.ServerOption) *AlloraAdapter {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	adapterpb.RegisterAdapterServiceServer(s, server)
+	go s.Serve(listener) // nolint: errcheck
+	t.Cleanup(s.Stop)
+
+	adapter := NewAlloraAdapter()
+	adapter.dialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})}
+	return adapter
+}
+
+func TestGrpcAdapterCallsService(t *testing.T) {
+	adapter := newTestAdapter(t, &testAdapterServer{})
+	ctx := context.Background()
+	worker := lib.WorkerConfig{TopicId: 1, Parameters: map[string]string{"GrpcEndpoint": "passthrough:///bufnet", "Token": "ETH"}}
+
+	inference, err := adapter.CalcInference(ctx, worker, 3600)
+	require.NoError(t, err)
+	assert.Equal(t, "ETH@01:00:00", inference)
+
+	forecasts, err := adapter.CalcForecast(ctx, worker, 3600)
+	require.NoError(t, err)
+	assert.Equal(t, []lib.NodeValue{{Worker: "allo1inferer", Value: "0.5"}}, forecasts)
+
+	reputer := lib.ReputerConfig{
+		TopicId:                1,
+		GroundTruthParameters:  map[string]string{"GrpcEndpoint": "passthrough:///bufnet"},
+		LossFunctionParameters: lib.LossFunctionParameters{LossFunctionService: "passthrough:///bufnet"},
+	}
+	truth, err := adapter.GroundTruth(ctx, reputer, 3600)
+	require.NoError(t, err)
+	assert.Equal(t, "10.5", truth)
+
+	loss, err := adapter.LossFunction(ctx, reputer, 100, "10.5", "9.5", map[string]string{"loss_method": "sqe"})
+	require.NoError(t, err)
+	assert.Equal(t, "10.5-9.5-sqe@100", loss)
+
+	// Methods the server does not implement fail with its status
+	_, err = adapter.IsLossFunctionNeverNegative(ctx, reputer, 100, nil)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	assert.False(t, lib.IsSourceUnavailable(err))
+	assert.Len(t, adapter.conns, 1, "one connection shared by all roles")
+
+	require.NoError(t, adapter.Close())
+	assert.Empty(t, adapter.conns)
+}
+
+func TestGrpcAdapterCallsAreDueByTheirTimeout(t *testing.T) {
+	adapter := newTestAdapter(t, &testAdapterServer{delay: time.Second})
+	worker := lib.WorkerConfig{Parameters: map[string]string{"GrpcEndpoint": "passthrough:///bufnet", "GrpcTimeoutSeconds": "0.05"}}
+
+	start := time.Now()
+	_, err := adapter.CalcInference(context.Background(), worker, 1)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.True(t, lib.IsSourceUnavailable(err), "retried on the next loop")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestGrpcAdapterReportsUnreachableServicesAsUnavailable(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	require.NoError(t, listener.Close())
+	adapter := NewAlloraAdapter()
+	adapter.dialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})}
+	reputer := lib.ReputerConfig{GroundTruthParameters: map[string]string{"GrpcEndpoint": "passthrough:///bufnet"}}
+
+	_, err := adapter.GroundTruth(context.Background(), reputer, 1)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	var unavailable *lib.SourceUnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, "passthrough:///bufnet", unavailable.Source)
+}
+
+func TestGrpcAdapterConnectsOverTls(t *testing.T) {
+	dir := t.TempDir()
+	serverCert := writeTestCertificate(t, dir, "adapter.test")
+	adapter := newTestAdapter(t, &testAdapterServer{}, grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{serverCert}})))
+
+	worker := lib.WorkerConfig{Parameters: map[string]string{
+		"GrpcEndpoint":   "passthrough:///bufnet",
+		"GrpcCaFile":     filepath.Join(dir, "cert.pem"),
+		"GrpcServerName": "adapter.test",
+		"Token":          "BTC",
+	}}
+	inference, err := adapter.CalcInference(context.Background(), worker, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "BTC@00:00:00", inference)
+
+	// Plaintext clients cannot talk to the TLS server
+	delete(worker.Parameters, "GrpcCaFile")
+	worker.Parameters["GrpcTimeoutSeconds"] = "0.2"
+	_, err = adapter.CalcInference(context.Background(), worker, 0)
+	assert.Error(t, err)
+}
+
+// Write a self-signed certificate for the host, used as its own CA, to cert.pem in the directory
+func writeTestCertificate(t *testing.T, dir string, host string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), certPem, 0o600))
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPem, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+	require.NoError(t, err)
+	return cert
+}
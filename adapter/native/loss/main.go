@@ -0,0 +1,306 @@
+package native_loss
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"errors"
+	"fmt"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	"github.com/rs/zerolog/log"
+)
+
+const DEFAULT_LOSS_METHOD = "sqe"
+
+const adapterName = "native-loss"
+
+func init() {
+	lib.RegisterAdapter(lib.AdapterRegistration{
+		Name: adapterName,
+		New:  func() lib.Adapter { return NewAlloraAdapter() },
+		Parameters: []lib.AdapterParameter{
+			{Name: "loss_method", Description: "sqe (default), abs, logcosh, huber, percentage, logloss or quantile", Capabilities: []lib.AdapterCapability{lib.AdapterCapabilityLossFunction}},
+			{Name: "delta", Description: "threshold of the huber loss, 1 by default", Capabilities: []lib.AdapterCapability{lib.AdapterCapabilityLossFunction}},
+			{Name: "epsilon", Description: "clipping of the predictions of the log loss", Capabilities: []lib.AdapterCapability{lib.AdapterCapabilityLossFunction}},
+			{Name: "quantile", Description: "quantile of the quantile loss, between 0 and 1", Capabilities: []lib.AdapterCapability{lib.AdapterCapabilityLossFunction}},
+		},
+	})
+}
+
+// Computes losses in process, with the loss function selected by the loss_method of LossMethodOptions.
+// Only computes losses: ground truth must come from another adapter.
+type AlloraAdapter struct {
+	name string
+}
+
+func (a *AlloraAdapter) Name() string {
+	return a.name
+}
+
+// A loss of a prediction against the ground truth, configured by the options
+type lossFunction struct {
+	loss            func(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error)
+	isNeverNegative bool
+}
+
+var lossFunctions = map[string]lossFunction{
+	"sqe":        {squaredError, true},
+	"abs":        {absoluteError, true},
+	"logcosh":    {logCoshError, true},
+	"huber":      {huberLoss, true},
+	"percentage": {percentageError, true},
+	"logloss":    {logLoss, true},
+	"quantile":   {quantileLoss, true},
+}
+
+// Loss function named by the loss_method option, squared error if none
+func lossFunctionOf(options map[string]string) (lossFunction, error) {
+	method := options["loss_method"]
+	if method == "" {
+		method = DEFAULT_LOSS_METHOD
+	}
+	fn, ok := lossFunctions[method]
+	if !ok {
+		return lossFunction{}, fmt.Errorf("unknown loss method: %s", method)
+	}
+	return fn, nil
+}
+
+// Decimal option, or the default if not set
+func decOption(options map[string]string, key string, defaultValue string) (alloraMath.Dec, error) {
+	value, ok := options[key]
+	if !ok || value == "" {
+		value = defaultValue
+	}
+	dec, err := alloraMath.NewDecFromString(value)
+	if err != nil {
+		return alloraMath.Dec{}, fmt.Errorf("invalid %s option %q: %w", key, value, err)
+	}
+	return dec, nil
+}
+
+// (prediction - groundTruth)^2
+func squaredError(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	diff, err := prediction.Sub(groundTruth)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return diff.Mul(diff)
+}
+
+// |prediction - groundTruth|
+func absoluteError(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	diff, err := prediction.Sub(groundTruth)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return diff.Abs()
+}
+
+// ln(cosh(d)) for d = prediction - groundTruth, computed as |d| + ln(1 + e^(-2|d|)) - ln(2) so it does not overflow
+func logCoshError(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	absDiff, err := absoluteError(groundTruth, prediction, options)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	exponent, err := absDiff.Mul(alloraMath.NewDecFromInt64(-2))
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	exp, err := alloraMath.Exp(exponent)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	onePlusExp, err := alloraMath.OneDec().Add(exp)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	lnOnePlusExp, err := alloraMath.Ln(onePlusExp)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	ln2, err := alloraMath.Ln(alloraMath.NewDecFromInt64(2))
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	loss, err := absDiff.Add(lnOnePlusExp)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return loss.Sub(ln2)
+}
+
+// d^2 / 2 for |d| <= delta, else delta * (|d| - delta / 2), for d = prediction - groundTruth.
+// delta is set by the delta option, 1 by default.
+func huberLoss(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	delta, err := decOption(options, "delta", "1")
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	if !delta.IsPositive() {
+		return alloraMath.Dec{}, errors.New("delta option must be positive")
+	}
+	absDiff, err := absoluteError(groundTruth, prediction, options)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	half := alloraMath.MustNewDecFromString("0.5")
+	if absDiff.Lte(delta) {
+		squared, err := absDiff.Mul(absDiff)
+		if err != nil {
+			return alloraMath.Dec{}, err
+		}
+		return squared.Mul(half)
+	}
+	halfDelta, err := delta.Mul(half)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	linear, err := absDiff.Sub(halfDelta)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return delta.Mul(linear)
+}
+
+// 100 * |prediction - groundTruth| / |groundTruth|. Undefined for a ground truth of 0.
+func percentageError(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	if groundTruth.IsZero() {
+		return alloraMath.Dec{}, errors.New("percentage error is undefined for a ground truth of 0")
+	}
+	absDiff, err := absoluteError(groundTruth, prediction, options)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	absTruth, err := groundTruth.Abs()
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	ratio, err := absDiff.Quo(absTruth)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return ratio.Mul(alloraMath.NewDecFromInt64(100))
+}
+
+// -(y ln(p) + (1 - y) ln(1 - p)) for a ground truth y in [0, 1] and a predicted probability p,
+// clipped to [epsilon, 1 - epsilon] so the loss stays finite. epsilon is set by the epsilon option, 1e-15 by default.
+func logLoss(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	one := alloraMath.OneDec()
+	if groundTruth.IsNegative() || groundTruth.Gt(one) {
+		return alloraMath.Dec{}, fmt.Errorf("log loss needs a ground truth between 0 and 1, got %s", groundTruth)
+	}
+	epsilon, err := decOption(options, "epsilon", "0.000000000000001")
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	if !epsilon.IsPositive() || epsilon.Gte(alloraMath.MustNewDecFromString("0.5")) {
+		return alloraMath.Dec{}, errors.New("epsilon option must be between 0 and 0.5")
+	}
+	oneMinusEpsilon, err := one.Sub(epsilon)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	p, err := alloraMath.Max(prediction, epsilon)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	p, err = alloraMath.Min(p, oneMinusEpsilon)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+
+	lnP, err := alloraMath.Ln(p)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	oneMinusP, err := one.Sub(p)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	lnOneMinusP, err := alloraMath.Ln(oneMinusP)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	oneMinusY, err := one.Sub(groundTruth)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	positive, err := groundTruth.Mul(lnP)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	negative, err := oneMinusY.Mul(lnOneMinusP)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	sum, err := positive.Add(negative)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return sum.Neg()
+}
+
+// max(q d, (q - 1) d) for d = groundTruth - prediction, i.e. the pinball loss of quantile q.
+// q is set by the quantile option, 0.5 by default.
+func quantileLoss(groundTruth, prediction alloraMath.Dec, options map[string]string) (alloraMath.Dec, error) {
+	q, err := decOption(options, "quantile", "0.5")
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	if !q.IsPositive() || q.Gte(alloraMath.OneDec()) {
+		return alloraMath.Dec{}, errors.New("quantile option must be between 0 and 1")
+	}
+	diff, err := groundTruth.Sub(prediction)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	under, err := q.Mul(diff)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	qMinusOne, err := q.Sub(alloraMath.OneDec())
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	over, err := qMinusOne.Mul(diff)
+	if err != nil {
+		return alloraMath.Dec{}, err
+	}
+	return alloraMath.Max(under, over)
+}
+
+func (a *AlloraAdapter) LossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, groundTruth string, inferenceValue string, options map[string]string) (string, error) {
+	fn, err := lossFunctionOf(options)
+	if err != nil {
+		return "", err
+	}
+	truth, err := alloraMath.NewDecFromString(groundTruth)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ground truth %q: %w", groundTruth, err)
+	}
+	prediction, err := alloraMath.NewDecFromString(inferenceValue)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prediction %q: %w", inferenceValue, err)
+	}
+	loss, err := fn.loss(truth, prediction, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute loss: %w", err)
+	}
+	log.Debug().Str("groundTruth", groundTruth).Str("prediction", inferenceValue).Str("loss", loss.String()).Msg("Calculated loss value natively")
+	return loss.String(), nil
+}
+
+func (a *AlloraAdapter) IsLossFunctionNeverNegative(ctx context.Context, node lib.ReputerConfig, blockHeight int64, options map[string]string) (bool, error) {
+	fn, err := lossFunctionOf(options)
+	if err != nil {
+		return false, err
+	}
+	return fn.isNeverNegative, nil
+}
+
+func NewAlloraAdapter() *AlloraAdapter {
+	return &AlloraAdapter{
+		name: adapterName,
+	}
+}
@@ -0,0 +1,65 @@
+package native_loss
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"testing"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireLoss(t *testing.T, expected string, groundTruth string, prediction string, options map[string]string) {
+	t.Helper()
+	loss, err := NewAlloraAdapter().LossFunction(context.Background(), lib.ReputerConfig{}, 100, groundTruth, prediction, options)
+	require.NoError(t, err)
+	actual := alloraMath.MustNewDecFromString(loss)
+	diff, err := actual.Sub(alloraMath.MustNewDecFromString(expected))
+	require.NoError(t, err)
+	diff, err = diff.Abs()
+	require.NoError(t, err)
+	assert.True(t, diff.Lt(alloraMath.MustNewDecFromString("0.000001")), "expected %s, got %s", expected, loss)
+}
+
+func TestLossFunctions(t *testing.T) {
+	requireLoss(t, "4", "3", "5", nil)
+	requireLoss(t, "4", "3", "5", map[string]string{"loss_method": "sqe"})
+	requireLoss(t, "2", "3", "1", map[string]string{"loss_method": "abs"})
+	requireLoss(t, "1.325003", "3", "5", map[string]string{"loss_method": "logcosh"})
+	requireLoss(t, "0", "3", "3", map[string]string{"loss_method": "logcosh"})
+	requireLoss(t, "0.125", "3", "3.5", map[string]string{"loss_method": "huber"})
+	requireLoss(t, "1.5", "3", "5", map[string]string{"loss_method": "huber"})
+	requireLoss(t, "2", "3", "5", map[string]string{"loss_method": "huber", "delta": "2"})
+	requireLoss(t, "25", "4", "5", map[string]string{"loss_method": "percentage"})
+	requireLoss(t, "0.356675", "1", "0.7", map[string]string{"loss_method": "logloss"})
+	requireLoss(t, "1.203973", "0", "0.7", map[string]string{"loss_method": "logloss"})
+	requireLoss(t, "1.8", "3", "1", map[string]string{"loss_method": "quantile", "quantile": "0.9"})
+	requireLoss(t, "0.2", "3", "5", map[string]string{"loss_method": "quantile", "quantile": "0.9"})
+}
+
+func TestLossFunctionErrors(t *testing.T) {
+	adapter := NewAlloraAdapter()
+	for _, options := range []map[string]string{
+		{"loss_method": "unknown"},
+		{"loss_method": "percentage"},
+		{"loss_method": "huber", "delta": "-1"},
+		{"loss_method": "quantile", "quantile": "1"},
+	} {
+		_, err := adapter.LossFunction(context.Background(), lib.ReputerConfig{}, 100, "0", "1", options)
+		assert.Error(t, err, options)
+	}
+	_, err := adapter.LossFunction(context.Background(), lib.ReputerConfig{}, 100, "2", "0.5", map[string]string{"loss_method": "logloss"})
+	assert.Error(t, err, "ground truth outside [0, 1]")
+}
+
+func TestIsLossFunctionNeverNegative(t *testing.T) {
+	adapter := NewAlloraAdapter()
+	for method := range lossFunctions {
+		neverNegative, err := adapter.IsLossFunctionNeverNegative(context.Background(), lib.ReputerConfig{}, 100, map[string]string{"loss_method": method})
+		require.NoError(t, err)
+		assert.True(t, neverNegative, method)
+	}
+	_, err := adapter.IsLossFunctionNeverNegative(context.Background(), lib.ReputerConfig{}, 100, map[string]string{"loss_method": "unknown"})
+	assert.Error(t, err)
+}
@@ -1,17 +1,36 @@
 package main
 
 import (
-	api_worker_reputer "allora_offchain_node/adapter/api/worker-reputer"
 	lib "allora_offchain_node/lib"
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	// Adapters register themselves with lib.RegisterAdapter when imported.
+	// Import other adapters here, or from another file of this package, to make them available.
+	_ "allora_offchain_node/adapter/api/worker-reputer"
+	_ "allora_offchain_node/adapter/exec/worker-reputer"
+	_ "allora_offchain_node/adapter/grpc/worker-reputer"
+	_ "allora_offchain_node/adapter/native/loss"
 )
 
-func NewAlloraAdapter(name string) (lib.AlloraAdapter, error) {
-	switch name {
-	case "api-worker-reputer":
-		return api_worker_reputer.NewAlloraAdapter(), nil
-	// Add other cases for different adapters here
-	default:
-		return nil, fmt.Errorf("unknown adapter name: %s", name)
+// Write the registered adapters with the roles they can fill and their parameters
+func PrintAdapters(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, registration := range lib.RegisteredAdapters() {
+		capabilities := []string{}
+		for _, capability := range registration.Capabilities() {
+			capabilities = append(capabilities, string(capability))
+		}
+		fmt.Fprintf(w, "%s\t%s\n", registration.Name, strings.Join(capabilities, ", "))
+		for _, parameter := range registration.Parameters {
+			required := ""
+			if parameter.Required {
+				required = " (required)"
+			}
+			fmt.Fprintf(w, "  %s%s\t%s\n", parameter.Name, required, parameter.Description)
+		}
 	}
+	return w.Flush()
 }
@@ -5,6 +5,65 @@ const ADDRESS_PREFIX = "allo" // each address prefixed by this
 const DEFAULT_BOND_DENOM = "uallo"
 const ALLORA_OFFCHAIN_NODE_CONFIG_JSON = "ALLORA_OFFCHAIN_NODE_CONFIG_JSON"
 const ALLORA_OFFCHAIN_NODE_CONFIG_FILE_PATH = "ALLORA_OFFCHAIN_NODE_CONFIG_FILE_PATH"
+const DEFAULT_SHUTDOWN_DRAIN_SECONDS = 30 // seconds in-flight submissions may keep running after shutdown is requested
+const METRICS_SERVER_SHUTDOWN_SECONDS = 5 // seconds to wait for the metrics server to stop
+const DEFAULT_SUPERVISOR_INITIAL_BACKOFF_SECONDS = 5
+const DEFAULT_SUPERVISOR_MAX_BACKOFF_SECONDS = 300
+const DEFAULT_SUBSCRIPTION_RECONNECT_SECONDS = 5
+const MAX_NONCE_LOOKUP_BACKOFF_BLOCKS = 8 // blocks between lookups of the next worker nonce of a topic whose epoch is late
+const DEFAULT_BATCHING_WINDOW_MILLISECONDS = 1000
+const DEFAULT_BATCHING_MAX_MSGS = 10
+const DEFAULT_BATCHING_MAX_GAS = 2000000
+const DEFAULT_RPC_PROBE_SECONDS = 10
+const DEFAULT_RPC_MAX_LAG_BLOCKS = 3
+const DEFAULT_CONFIRMATION_TIMEOUT_SECONDS = 30
+const DEFAULT_CONFIRMATION_MAX_REQUEUES = 2
+const DEFAULT_FEE_ORACLE_WINDOW = 20              // included txs the gas price oracle estimates from
+const DEFAULT_FEE_ORACLE_REFRESH_SECONDS = 60     // seconds the minimum gas price of the chain is cached for
+const DEFAULT_FEE_ORACLE_SAMPLE_SECONDS = 5       // seconds between samples of the gas prices of the latest block
+const DEFAULT_FEE_DEADLINE_SECONDS = 20           // seconds before its deadline a tx is paid more for
+const DEFAULT_FEE_DEADLINE_MULTIPLIER float64 = 2 // gas price multiplier close to the deadline
+const DEFAULT_LEDGER_PATH = "submission_ledger.db"
+const DEFAULT_LEDGER_RETENTION_DAYS = 30                  // days submissions are kept in the ledger after their last update
+const LEDGER_PRUNE_INTERVAL_SECONDS = 3600                // seconds between prunings of the submission ledger
+const DEFAULT_REPUTER_MAX_NONCES_PER_CYCLE = 10           // unfulfilled reputer nonces caught up on per loop
+const DEFAULT_LOSS_CONCURRENCY = 4                        // losses of a bundle computed concurrently per reputer
+const DEFAULT_ADAPTER_HTTP_TIMEOUT_SECONDS = 30           // seconds each attempt of an adapter http call may take
+const DEFAULT_ADAPTER_HTTP_MAX_RETRIES = 2                // retries of failed idempotent adapter http calls
+const DEFAULT_ADAPTER_HTTP_RETRY_DELAY_MILLISECONDS = 500 // base of the jittered exponential delay between retries
+const DEFAULT_ADAPTER_BREAKER_FAILURES = 5                // consecutive failures opening the circuit of an endpoint
+const DEFAULT_ADAPTER_BREAKER_OPEN_SECONDS = 30           // seconds an open circuit rejects calls before letting one through
+
+// Parameter the active inferers of the topic are passed to forecast adapters under, comma separated,
+// for workers targeting active inferers
+const ActiveInferersParameter = "ActiveInferers"
+
+// Orders in which a reputer works through the unfulfilled nonces of its topic
+const (
+	ReputerNonceOrderOldestFirst string = "oldest-first"
+	ReputerNonceOrderNewestFirst string = "newest-first"
+)
+
+// Strategies to price the gas of transactions with
+const (
+	FeeStrategyFixed                string = "fixed"
+	FeeStrategyOracle               string = "oracle"
+	FeeStrategyAggressiveOnDeadline string = "aggressive-on-deadline"
+)
+
+// Policies for inferences violating the guards of their worker
+const (
+	InferenceGuardPolicySkip     string = "skip"
+	InferenceGuardPolicyClamp    string = "clamp"
+	InferenceGuardPolicyFallback string = "fallback"
+)
+
+// States of the circuit breaker of an adapter endpoint
+const (
+	BreakerStateClosed   string = "closed"
+	BreakerStateOpen     string = "open"
+	BreakerStateHalfOpen string = "half-open"
+)
 
 const (
 	InferenceRequestCount       string = "allora_worker_inference_request_count"
@@ -14,6 +73,10 @@ const (
 	ReputerDataBuildCount       string = "allora_reputer_data_build_count"
 	WorkerChainSubmissionCount  string = "allora_worker_chain_submission_count"
 	ReputerChainSubmissionCount string = "allora_reputer_chain_submission_count"
+	WorkerMissedNonceCount      string = "allora_worker_missed_nonce_count"
+	ReputerMissedNonceCount     string = "allora_reputer_missed_nonce_count"
+	RpcEndpointActive           string = "allora_rpc_endpoint_active"
+	AdapterBreakerStateChanges  string = "allora_adapter_breaker_state_change_count"
 )
 
 // A struct that holds the name and help text for a prometheus counter
@@ -25,6 +88,8 @@ var COUNTER_DATA = []MetricsCounter{
 	{ReputerDataBuildCount, "The total number of times worker built data successfully"},
 	{WorkerChainSubmissionCount, "The total number of worker commits to the chain"},
 	{ReputerChainSubmissionCount, "The total number of reputer commits to the chain"},
+	{WorkerMissedNonceCount, "The total number of worker nonces missed because their submission window closed"},
+	{ReputerMissedNonceCount, "The total number of reputer nonces missed because their submission window closed"},
 }
 
 type ConfigStruct struct {
@@ -40,4 +105,4 @@ type WalletInfo struct {
 var (
 	LOCALIP       string
 	CONFIG_STRUCT ConfigStruct
-)
\ No newline at end of file
+)
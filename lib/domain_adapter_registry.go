@@ -0,0 +1,179 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Role an adapter can fill for a worker or reputer
+type AdapterCapability string
+
+const (
+	AdapterCapabilityInference    AdapterCapability = "inference"
+	AdapterCapabilityForecast     AdapterCapability = "forecast"
+	AdapterCapabilityGroundTruth  AdapterCapability = "groundTruth"
+	AdapterCapabilityLossFunction AdapterCapability = "lossFunction"
+)
+
+// Parameter an adapter reads from the parameters of the worker or reputer it serves.
+// Inference and forecast parameters are read from `parameters`, ground truth ones from `groundTruthParameters`
+// and loss function ones from `lossFunctionParameters`: `LossFunctionService` and the `LossMethodOptions`.
+type AdapterParameter struct {
+	Name         string
+	Description  string
+	Required     bool
+	Capabilities []AdapterCapability // roles the parameter is read for
+}
+
+// An adapter as registered by its package: how to construct it and its config schema.
+// The roles it can fill are those of the role interfaces it implements.
+type AdapterRegistration struct {
+	Name       string
+	New        func() Adapter
+	Parameters []AdapterParameter
+}
+
+// Roles the adapter can fill
+func (r AdapterRegistration) Capabilities() []AdapterCapability {
+	return AdapterCapabilities(r.New())
+}
+
+var adapterRegistry = struct {
+	mu       sync.RWMutex
+	adapters map[string]AdapterRegistration
+	// Adapters constructed so far, closed by CloseAdapters
+	constructed []Adapter
+}{adapters: map[string]AdapterRegistration{}}
+
+// Register an adapter by name, to be called from the init function of its package.
+// Panics if the registration is incomplete or the name is taken, as both are programming errors.
+func RegisterAdapter(registration AdapterRegistration) {
+	if registration.Name == "" || registration.New == nil {
+		panic("adapter registration needs a name and a constructor")
+	}
+	adapterRegistry.mu.Lock()
+	defer adapterRegistry.mu.Unlock()
+	if _, taken := adapterRegistry.adapters[registration.Name]; taken {
+		panic(fmt.Sprintf("adapter %s registered twice", registration.Name))
+	}
+	adapterRegistry.adapters[registration.Name] = registration
+}
+
+// Registered adapters, by name
+func RegisteredAdapters() []AdapterRegistration {
+	adapterRegistry.mu.RLock()
+	defer adapterRegistry.mu.RUnlock()
+	registrations := make([]AdapterRegistration, 0, len(adapterRegistry.adapters))
+	for _, registration := range adapterRegistry.adapters {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool { return registrations[i].Name < registrations[j].Name })
+	return registrations
+}
+
+// Construct the adapter registered under the name to fill the role, after checking the parameters
+// hold all parameters it requires for it. Whether it can fill the role is up to the caller.
+func newAdapter(name string, capability AdapterCapability, parameters map[string]string) (Adapter, error) {
+	adapterRegistry.mu.RLock()
+	registration, ok := adapterRegistry.adapters[name]
+	adapterRegistry.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown adapter name: %s", name)
+	}
+	for _, parameter := range registration.Parameters {
+		if !parameter.Required || !parameter.appliesTo(capability) {
+			continue
+		}
+		if parameters[parameter.Name] == "" {
+			return nil, fmt.Errorf("adapter %s requires parameter %s for %s", name, parameter.Name, capability)
+		}
+	}
+	adapter := registration.New()
+	adapterRegistry.mu.Lock()
+	adapterRegistry.constructed = append(adapterRegistry.constructed, adapter)
+	adapterRegistry.mu.Unlock()
+	return adapter, nil
+}
+
+// Release the resources held by the adapters constructed so far, e.g. connections, once they are no longer used.
+// Adapters holding resources implement io.Closer.
+func CloseAdapters() error {
+	adapterRegistry.mu.Lock()
+	constructed := adapterRegistry.constructed
+	adapterRegistry.constructed = nil
+	adapterRegistry.mu.Unlock()
+
+	var errs []error
+	for _, adapter := range constructed {
+		if closer, ok := adapter.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close adapter %s: %w", adapter.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Construct the adapter registered under the name as the inferer of a worker with the parameters
+func ResolveInferer(name string, parameters map[string]string) (Inferer, error) {
+	adapter, err := newAdapter(name, AdapterCapabilityInference, parameters)
+	if err != nil {
+		return nil, err
+	}
+	inferer, ok := AsInferer(adapter)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s cannot be used for %s", name, AdapterCapabilityInference)
+	}
+	return inferer, nil
+}
+
+// Construct the adapter registered under the name as the forecaster of a worker with the parameters
+func ResolveForecaster(name string, parameters map[string]string) (Forecaster, error) {
+	adapter, err := newAdapter(name, AdapterCapabilityForecast, parameters)
+	if err != nil {
+		return nil, err
+	}
+	forecaster, ok := AsForecaster(adapter)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s cannot be used for %s", name, AdapterCapabilityForecast)
+	}
+	return forecaster, nil
+}
+
+// Construct the adapter registered under the name as the ground truth source of a reputer with the parameters
+func ResolveGroundTruthSource(name string, parameters map[string]string) (GroundTruthSource, error) {
+	adapter, err := newAdapter(name, AdapterCapabilityGroundTruth, parameters)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := AsGroundTruthSource(adapter)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s cannot be used for %s", name, AdapterCapabilityGroundTruth)
+	}
+	return source, nil
+}
+
+// Construct the adapter registered under the name as the loss function of a reputer with the parameters
+func ResolveLossFunction(name string, parameters map[string]string) (LossFunction, error) {
+	adapter, err := newAdapter(name, AdapterCapabilityLossFunction, parameters)
+	if err != nil {
+		return nil, err
+	}
+	lossFunction, ok := AsLossFunction(adapter)
+	if !ok {
+		return nil, fmt.Errorf("adapter %s cannot be used for %s", name, AdapterCapabilityLossFunction)
+	}
+	return lossFunction, nil
+}
+
+func (p AdapterParameter) appliesTo(capability AdapterCapability) bool {
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testInferer struct{}
+
+func (a *testInferer) Name() string { return "test-inferer" }
+
+func (a *testInferer) CalcInference(ctx context.Context, worker WorkerConfig, blockHeight int64) (string, error) {
+	return "1", nil
+}
+
+// Adapter implementing the deprecated AlloraAdapter, filling only the forecast role
+type testLegacyForecaster struct {
+	testInferer
+}
+
+func (a *testLegacyForecaster) CalcForecast(ctx context.Context, worker WorkerConfig, blockHeight int64) ([]NodeValue, error) {
+	return nil, nil
+}
+
+func (a *testLegacyForecaster) GroundTruth(ctx context.Context, reputer ReputerConfig, blockHeight int64) (Truth, error) {
+	return "", nil
+}
+
+func (a *testLegacyForecaster) LossFunction(ctx context.Context, reputer ReputerConfig, blockHeight int64, groundTruth string, value string, options map[string]string) (string, error) {
+	return "", nil
+}
+
+func (a *testLegacyForecaster) IsLossFunctionNeverNegative(ctx context.Context, reputer ReputerConfig, blockHeight int64, options map[string]string) (bool, error) {
+	return true, nil
+}
+
+func (a *testLegacyForecaster) CanInfer() bool                           { return false }
+func (a *testLegacyForecaster) CanForecast() bool                        { return true }
+func (a *testLegacyForecaster) CanSourceGroundTruthAndComputeLoss() bool { return false }
+
+func TestAdapterRegistryResolvesRegisteredAdapters(t *testing.T) {
+	constructed := 0
+	RegisterAdapter(AdapterRegistration{
+		Name: "test-inferer",
+		New:  func() Adapter { constructed++; return &testInferer{} },
+		Parameters: []AdapterParameter{
+			{Name: "InferenceEndpoint", Required: true, Capabilities: []AdapterCapability{AdapterCapabilityInference}},
+			{Name: "Token", Capabilities: []AdapterCapability{AdapterCapabilityInference}},
+		},
+	})
+
+	inferer, err := ResolveInferer("test-inferer", map[string]string{"InferenceEndpoint": "http://localhost"})
+	require.NoError(t, err)
+	assert.Equal(t, "test-inferer", inferer.Name())
+	assert.Equal(t, 1, constructed)
+
+	_, err = ResolveInferer("test-inferer", map[string]string{"Token": "ETH"})
+	assert.ErrorContains(t, err, "requires parameter InferenceEndpoint")
+	_, err = ResolveLossFunction("test-inferer", nil)
+	assert.ErrorContains(t, err, "cannot be used for lossFunction")
+	_, err = ResolveInferer("unknown", nil)
+	assert.ErrorContains(t, err, "unknown adapter name: unknown")
+
+	var registration AdapterRegistration
+	for _, r := range RegisteredAdapters() {
+		if r.Name == "test-inferer" {
+			registration = r
+		}
+	}
+	assert.Equal(t, []AdapterCapability{AdapterCapabilityInference}, registration.Capabilities())
+
+	assert.Panics(t, func() {
+		RegisterAdapter(AdapterRegistration{Name: "test-inferer", New: func() Adapter { return &testInferer{} }})
+	})
+	assert.Panics(t, func() { RegisterAdapter(AdapterRegistration{Name: "test-unconstructible"}) })
+}
+
+type testClosingInferer struct {
+	testInferer
+	closed int
+}
+
+func (a *testClosingInferer) Close() error {
+	a.closed++
+	return nil
+}
+
+func TestCloseAdaptersClosesConstructedAdapters(t *testing.T) {
+	adapter := &testClosingInferer{}
+	RegisterAdapter(AdapterRegistration{Name: "test-closing-inferer", New: func() Adapter { return adapter }})
+	_, err := ResolveInferer("test-closing-inferer", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, CloseAdapters())
+	assert.Equal(t, 1, adapter.closed)
+	require.NoError(t, CloseAdapters())
+	assert.Equal(t, 1, adapter.closed, "closed once")
+}
+
+func TestLegacyAdaptersFillTheRolesTheyCan(t *testing.T) {
+	legacy := &testLegacyForecaster{}
+	_, ok := AsInferer(legacy)
+	assert.False(t, ok)
+	_, ok = AsForecaster(legacy)
+	assert.True(t, ok)
+	_, ok = AsGroundTruthSource(legacy)
+	assert.False(t, ok)
+	assert.Equal(t, []AdapterCapability{AdapterCapabilityForecast, AdapterCapabilityLossFunction}, AdapterCapabilities(legacy))
+	assert.Equal(t, []AdapterCapability{AdapterCapabilityInference}, AdapterCapabilities(&testInferer{}))
+}
+
+func TestLossFunctionAdapterParameters(t *testing.T) {
+	parameters := LossFunctionParameters{
+		LossFunctionService: "http://localhost:5000",
+		LossMethodOptions:   map[string]string{"loss_method": "huber"},
+	}.AdapterParameters()
+	assert.Equal(t, map[string]string{"loss_method": "huber", "LossFunctionService": "http://localhost:5000"}, parameters)
+}
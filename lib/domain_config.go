@@ -1,6 +1,12 @@
 package lib
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
 	emissions "github.com/allora-network/allora-chain/x/emissions/types"
 	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
@@ -13,23 +19,36 @@ type WalletConfig struct {
 	Address                   string // will be overwritten by the keystore. This is the 1 value that is auto-generated in this struct
 	AddressKeyName            string // load a address by key from the keystore
 	AddressRestoreMnemonic    string
-	AlloraHomeDir             string  // home directory for the allora keystore
-	Gas                       string  // gas to use for the allora client
-	GasAdjustment             float64 // gas adjustment to use for the allora client
-	GasPrices                 float64 // gas prices to use for the allora client - 0 for no fees
-	MaxFees                   uint64  // max gas to use for the allora client
-	NodeRpc                   string  // rpc node for allora chain
-	MaxRetries                int64   // retry to get data from chain up to this many times per query or tx
-	RetryDelay                int64   // number of seconds to wait between retries (general case)
-	AccountSequenceRetryDelay int64   // number of seconds to wait between retries in case of account sequence error
-	SubmitTx                  bool    // useful for dev/testing. set to false to run in dry-run processes without committing to the chain
+	AlloraHomeDir             string   // home directory for the allora keystore
+	Gas                       string   // gas to use for the allora client
+	GasAdjustment             float64  // gas adjustment to use for the allora client
+	GasPrices                 float64  // gas prices to use for the allora client - 0 for no fees
+	MaxFees                   uint64   // max gas to use for the allora client
+	NodeRpc                   string   // rpc node for allora chain
+	NodeRpcs                  []string // additional rpc nodes to fail over to
+	MaxRetries                int64    // retry to get data from chain up to this many times per query or tx
+	RetryDelay                int64    // number of seconds to wait between retries (general case)
+	AccountSequenceRetryDelay int64    // number of seconds to wait between retries in case of account sequence error
+	SubmitTx                  bool     // useful for dev/testing. set to false to run in dry-run processes without committing to the chain
+}
+
+// Rpc nodes of the chain, NodeRpc first, without duplicates
+func (c WalletConfig) RpcEndpoints() []string {
+	endpoints := []string{}
+	for _, nodeRpc := range append([]string{c.NodeRpc}, c.NodeRpcs...) {
+		if nodeRpc != "" && !slices.Contains(endpoints, nodeRpc) {
+			endpoints = append(endpoints, nodeRpc)
+		}
+	}
+	return endpoints
 }
 
 // Properties auto-generated based on what the user has provided in WalletConfig fields of UserConfig
 type ChainConfig struct {
 	Address              string // will be auto-generated based on the keystore
 	Account              cosmosaccount.Account
-	Client               *cosmosclient.Client
+	Client               *cosmosclient.Client // client of the first reachable rpc node, holding the keyring
+	Rpc                  *RpcPool             // rpc nodes queries and broadcasts go to
 	EmissionsQueryClient emissions.QueryServiceClient
 	BankQueryClient      bank.QueryClient
 	DefaultBondDenom     string
@@ -39,19 +58,71 @@ type ChainConfig struct {
 type WorkerConfig struct {
 	TopicId                 emissions.TopicId
 	InferenceEntrypointName string
-	InferenceEntrypoint     AlloraAdapter
+	InferenceEntrypoint     Inferer
 	ForecastEntrypointName  string
-	ForecastEntrypoint      AlloraAdapter
+	ForecastEntrypoint      Forecaster
 	LoopSeconds             int64             // seconds to wait between attempts to get next worker nonce
 	Parameters              map[string]string // Map for variable configuration values
+	// Pass the active inferers of the topic to the forecast adapter, under the ActiveInferers parameter,
+	// and drop forecasts for any other worker. The chain only tells the inferers active now, not those of
+	// a given nonce, so forecasts for an older nonce target the current inferers.
+	TargetActiveInferers bool
+	InferenceGuards      InferenceGuardConfig // sanity checks of the inferences before they are submitted
+}
+
+// Sanity checks of the inferences of a worker. Inferences that are not finite numbers are always rejected,
+// the other checks are only made when configured.
+type InferenceGuardConfig struct {
+	MinValue          string  // lowest inference accepted, as a decimal. Empty for no lower bound
+	MaxValue          string  // highest inference accepted, as a decimal. Empty for no upper bound
+	MaxRelativeChange float64 // largest change accepted relative to the previous inference submitted, e.g. 0.2 for 20%. 0 to not check
+	StaleRepeats      int64   // inferences are stale once the source returned the same value this many times in a row. 0 to not check
+	// What to do with inferences violating the checks: "skip" (default) to not submit them, "clamp" to submit them
+	// clamped within the bounds and relative change, or "fallback" to submit the inference of the fallback entrypoint
+	Policy                 string
+	FallbackEntrypointName string
+	FallbackEntrypoint     Inferer
+	FallbackParameters     map[string]string // parameters of the fallback entrypoint, the parameters of the worker if empty
+}
+
+// What to do with inferences violating the checks
+func (c InferenceGuardConfig) GuardPolicy() string {
+	if c.Policy == "" {
+		return InferenceGuardPolicySkip
+	}
+	return c.Policy
+}
+
+// Lowest and highest inferences accepted, nil when unbounded
+func (c InferenceGuardConfig) Bounds() (*alloraMath.Dec, *alloraMath.Dec, error) {
+	var bounds [2]*alloraMath.Dec
+	for i, value := range []string{c.MinValue, c.MaxValue} {
+		if value == "" {
+			continue
+		}
+		bound, err := alloraMath.NewDecFromString(value)
+		if err != nil || !bound.IsFinite() {
+			return nil, nil, fmt.Errorf("invalid inference bound %q", value)
+		}
+		bounds[i] = &bound
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// Parameters of the fallback entrypoint
+func (c InferenceGuardConfig) FallbackEntrypointParameters(worker WorkerConfig) map[string]string {
+	if len(c.FallbackParameters) == 0 {
+		return worker.Parameters
+	}
+	return c.FallbackParameters
 }
 
 type ReputerConfig struct {
 	TopicId                    emissions.TopicId
 	GroundTruthEntrypointName  string
-	GroundTruthEntrypoint      AlloraAdapter
+	GroundTruthEntrypoint      GroundTruthSource
 	LossFunctionEntrypointName string
-	LossFunctionEntrypoint     AlloraAdapter
+	LossFunctionEntrypoint     LossFunction
 	// Minimum stake to repute. will try to add stake from wallet if current stake is less than this.
 	// Will not repute if current stake is less than this, after trying to add any necessary stake.
 	// This is idempotent in that it will not add more stake than specified here.
@@ -60,6 +131,33 @@ type ReputerConfig struct {
 	LoopSeconds            int64                  // seconds to wait between attempts to get next reptuer nonces
 	GroundTruthParameters  map[string]string      // Map for variable configuration values
 	LossFunctionParameters LossFunctionParameters // Map for variable configuration values
+	NonceOrder             string                 // order to work through unfulfilled nonces in, "oldest-first" (default) or "newest-first"
+	MaxNoncesPerCycle      int64                  // unfulfilled nonces to submit for per loop at most. 0 to use the default
+	LossConcurrency        int64                  // losses computed concurrently at most when computed one value at a time. 0 to use the default
+}
+
+// Order to work through the unfulfilled nonces of the topic in
+func (c ReputerConfig) ReputerNonceOrder() string {
+	if c.NonceOrder == "" {
+		return ReputerNonceOrderOldestFirst
+	}
+	return c.NonceOrder
+}
+
+// Unfulfilled nonces to submit for per loop at most
+func (c ReputerConfig) MaxReputerNoncesPerCycle() int64 {
+	if c.MaxNoncesPerCycle <= 0 {
+		return DEFAULT_REPUTER_MAX_NONCES_PER_CYCLE
+	}
+	return c.MaxNoncesPerCycle
+}
+
+// Losses computed concurrently at most when computed one value at a time
+func (c ReputerConfig) MaxLossConcurrency() int {
+	if c.LossConcurrency <= 0 {
+		return DEFAULT_LOSS_CONCURRENCY
+	}
+	return int(c.LossConcurrency)
 }
 
 type LossFunctionParameters struct {
@@ -68,17 +166,308 @@ type LossFunctionParameters struct {
 	IsNeverNegative     *bool // Cached result of whether the loss function is never negative
 }
 
+// Parameters as checked against the config schema of the loss function adapter: the loss method options and the service
+func (p LossFunctionParameters) AdapterParameters() map[string]string {
+	parameters := map[string]string{}
+	for key, value := range p.LossMethodOptions {
+		parameters[key] = value
+	}
+	if p.LossFunctionService != "" {
+		parameters["LossFunctionService"] = p.LossFunctionService
+	}
+	return parameters
+}
+
+// Properties of the automatic topic discovery mode.
+// When enabled, actors are spawned from the templates for every active topic on chain that passes the filters,
+// in addition to the topics explicitly listed in Worker and Reputer.
+type TopicDiscoveryConfig struct {
+	Enabled         bool
+	LoopSeconds     int64               // seconds to wait between polls of the active topics on chain
+	WorkerTemplate  *WorkerConfig       // worker config to clone per discovered topic. nil to not spawn workers
+	ReputerTemplate *ReputerConfig      // reputer config to clone per discovered topic. nil to not spawn reputers
+	AllowTopicIds   []emissions.TopicId // if not empty, only these topics may be discovered
+	DenyTopicIds    []emissions.TopicId // these topics are never discovered
+	MetadataRegex   string              // if not empty, only topics whose metadata matches this regex are discovered
+}
+
+// Properties of the graceful shutdown on SIGINT/SIGTERM
+type ShutdownConfig struct {
+	DrainSeconds int64 // seconds in-flight submissions may keep running after shutdown is requested. 0 to use the default
+}
+
+// Time in-flight submissions may keep running after shutdown is requested
+func (c ShutdownConfig) DrainTimeout() time.Duration {
+	if c.DrainSeconds <= 0 {
+		return DEFAULT_SHUTDOWN_DRAIN_SECONDS * time.Second
+	}
+	return time.Duration(c.DrainSeconds) * time.Second
+}
+
+// Properties of the supervisor restarting worker and reputer processes that exited or panicked
+type SupervisorConfig struct {
+	InitialBackoffSeconds int64 // seconds to wait before the first restart. 0 to use the default
+	MaxBackoffSeconds     int64 // upper bound on the exponentially growing wait between restarts. 0 to use the default
+}
+
+// Wait before the first restart of a process
+func (c SupervisorConfig) InitialBackoff() time.Duration {
+	if c.InitialBackoffSeconds <= 0 {
+		return DEFAULT_SUPERVISOR_INITIAL_BACKOFF_SECONDS * time.Second
+	}
+	return time.Duration(c.InitialBackoffSeconds) * time.Second
+}
+
+// Upper bound on the wait between restarts of a process
+func (c SupervisorConfig) MaxBackoff() time.Duration {
+	if c.MaxBackoffSeconds <= 0 {
+		return DEFAULT_SUPERVISOR_MAX_BACKOFF_SECONDS * time.Second
+	}
+	return time.Duration(c.MaxBackoffSeconds) * time.Second
+}
+
+// Properties of the event-driven nonce detection.
+// When enabled, actors are woken by new blocks received over the node's websocket instead of polling for nonces,
+// and fall back to polling every LoopSeconds while the subscription is down.
+type SubscriptionConfig struct {
+	Enabled          bool
+	WebsocketUrl     string // websocket of the node. Derived from the active rpc node if empty
+	ReconnectSeconds int64  // seconds to wait before resubscribing after the subscription dropped. 0 to use the default
+}
+
+// Wait before resubscribing after the subscription dropped
+func (c SubscriptionConfig) ReconnectDelay() time.Duration {
+	if c.ReconnectSeconds <= 0 {
+		return DEFAULT_SUBSCRIPTION_RECONNECT_SECONDS * time.Second
+	}
+	return time.Duration(c.ReconnectSeconds) * time.Second
+}
+
+// Properties of the health probes of the rpc nodes, which rank them for queries and broadcasts
+type RpcHealthConfig struct {
+	ProbeSeconds int64 // seconds between health probes. 0 to use the default
+	MaxLagBlocks int64 // blocks a node may lag the highest node by and still be healthy. 0 to use the default
+}
+
+// Time between health probes
+func (c RpcHealthConfig) ProbeInterval() time.Duration {
+	if c.ProbeSeconds <= 0 {
+		return DEFAULT_RPC_PROBE_SECONDS * time.Second
+	}
+	return time.Duration(c.ProbeSeconds) * time.Second
+}
+
+// Blocks a node may lag the highest node by and still be healthy
+func (c RpcHealthConfig) MaxLag() int64 {
+	if c.MaxLagBlocks <= 0 {
+		return DEFAULT_RPC_MAX_LAG_BLOCKS
+	}
+	return c.MaxLagBlocks
+}
+
+// Properties of the batching of payloads into multi-message transactions.
+// When enabled, payloads queued within the window are broadcast together in one tx, paying the base fees once.
+type BatchingConfig struct {
+	Enabled            bool
+	WindowMilliseconds int64  // time to collect payloads for a batch. 0 to use the default
+	MaxMsgs            int64  // payloads per batch at most. 0 to use the default
+	MaxGas             uint64 // simulated gas per batch at most. 0 to use the default
+}
+
+// Time to collect payloads for a batch
+func (c BatchingConfig) Window() time.Duration {
+	if c.WindowMilliseconds <= 0 {
+		return DEFAULT_BATCHING_WINDOW_MILLISECONDS * time.Millisecond
+	}
+	return time.Duration(c.WindowMilliseconds) * time.Millisecond
+}
+
+// Payloads per batch at most
+func (c BatchingConfig) MaxBatchMsgs() int {
+	if c.MaxMsgs <= 0 {
+		return DEFAULT_BATCHING_MAX_MSGS
+	}
+	return int(c.MaxMsgs)
+}
+
+// Simulated gas per batch at most
+func (c BatchingConfig) MaxBatchGas() uint64 {
+	if c.MaxGas == 0 {
+		return DEFAULT_BATCHING_MAX_GAS
+	}
+	return c.MaxGas
+}
+
+// Properties of the pricing of transaction gas
+type FeeConfig struct {
+	Strategy           string  // fixed, oracle or aggressive-on-deadline. Defaults to fixed
+	OracleWindow       int64   // included txs the oracle estimates gas prices from. 0 to use the default
+	DeadlineSeconds    int64   // seconds before its deadline a tx is paid more for, with aggressive-on-deadline. 0 to use the default
+	DeadlineMultiplier float64 // gas price multiplier close to the deadline, with aggressive-on-deadline. 0 to use the default
+}
+
+// Strategy to price gas with
+func (c FeeConfig) FeeStrategy() string {
+	if c.Strategy == "" {
+		return FeeStrategyFixed
+	}
+	return c.Strategy
+}
+
+// Included txs the oracle estimates gas prices from
+func (c FeeConfig) Window() int {
+	if c.OracleWindow <= 0 {
+		return DEFAULT_FEE_ORACLE_WINDOW
+	}
+	return int(c.OracleWindow)
+}
+
+// Time before its deadline a tx is paid more for
+func (c FeeConfig) DeadlineWindow() time.Duration {
+	if c.DeadlineSeconds <= 0 {
+		return DEFAULT_FEE_DEADLINE_SECONDS * time.Second
+	}
+	return time.Duration(c.DeadlineSeconds) * time.Second
+}
+
+// Gas price multiplier close to the deadline
+func (c FeeConfig) DeadlineGasPriceMultiplier() float64 {
+	if c.DeadlineMultiplier <= 0 {
+		return DEFAULT_FEE_DEADLINE_MULTIPLIER
+	}
+	return c.DeadlineMultiplier
+}
+
+// Properties of the confirmation of broadcast transactions
+type ConfirmationConfig struct {
+	TimeoutSeconds int64 // seconds to wait for a broadcast tx to be included in a block. 0 to use the default
+	MaxRequeues    int64 // times a payload never included is queued again. 0 to use the default, negative to never queue it again
+}
+
+// Time to wait for a broadcast tx to be included in a block
+func (c ConfirmationConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return DEFAULT_CONFIRMATION_TIMEOUT_SECONDS * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Times a payload never included is queued again
+func (c ConfirmationConfig) MaxRequeueCount() int64 {
+	if c.MaxRequeues < 0 {
+		return 0
+	}
+	if c.MaxRequeues == 0 {
+		return DEFAULT_CONFIRMATION_MAX_REQUEUES
+	}
+	return c.MaxRequeues
+}
+
+// Properties of the submission ledger
+type LedgerConfig struct {
+	Path          string // file of the ledger. Defaults to submission_ledger.db in the working directory
+	RetentionDays int64  // days submissions are kept after their last update. 0 to use the default
+}
+
+// File of the submission ledger
+func (c LedgerConfig) DbPath() string {
+	if c.Path == "" {
+		return DEFAULT_LEDGER_PATH
+	}
+	return c.Path
+}
+
+// Age after which submissions are pruned from the ledger
+func (c LedgerConfig) Retention() time.Duration {
+	if c.RetentionDays <= 0 {
+		return DEFAULT_LEDGER_RETENTION_DAYS * 24 * time.Hour
+	}
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// Properties of the http client shared by the adapters calling models, ground truth sources and loss function services
+type AdapterHttpConfig struct {
+	TimeoutSeconds          int64 // seconds each attempt of a call may take. 0 to use the default
+	MaxRetries              int64 // retries of failed idempotent calls. 0 to use the default, negative to never retry
+	RetryDelayMilliseconds  int64 // base of the jittered exponential delay between retries. 0 to use the default
+	BreakerFailureThreshold int64 // consecutive failures opening the circuit of an endpoint. 0 to use the default
+	BreakerOpenSeconds      int64 // seconds an open circuit rejects calls before letting one through. 0 to use the default
+}
+
+// Time each attempt of a call may take
+func (c AdapterHttpConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return DEFAULT_ADAPTER_HTTP_TIMEOUT_SECONDS * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Retries of failed idempotent calls
+func (c AdapterHttpConfig) MaxRetryCount() int {
+	if c.MaxRetries < 0 {
+		return 0
+	}
+	if c.MaxRetries == 0 {
+		return DEFAULT_ADAPTER_HTTP_MAX_RETRIES
+	}
+	return int(c.MaxRetries)
+}
+
+// Base of the delay between retries
+func (c AdapterHttpConfig) RetryDelay() time.Duration {
+	if c.RetryDelayMilliseconds <= 0 {
+		return DEFAULT_ADAPTER_HTTP_RETRY_DELAY_MILLISECONDS * time.Millisecond
+	}
+	return time.Duration(c.RetryDelayMilliseconds) * time.Millisecond
+}
+
+// Consecutive failures opening the circuit of an endpoint
+func (c AdapterHttpConfig) BreakerFailures() int {
+	if c.BreakerFailureThreshold <= 0 {
+		return DEFAULT_ADAPTER_BREAKER_FAILURES
+	}
+	return int(c.BreakerFailureThreshold)
+}
+
+// Time an open circuit rejects calls before letting one through
+func (c AdapterHttpConfig) BreakerOpenDuration() time.Duration {
+	if c.BreakerOpenSeconds <= 0 {
+		return DEFAULT_ADAPTER_BREAKER_OPEN_SECONDS * time.Second
+	}
+	return time.Duration(c.BreakerOpenSeconds) * time.Second
+}
+
 type UserConfig struct {
-	Wallet  WalletConfig
-	Worker  []WorkerConfig
-	Reputer []ReputerConfig
+	Wallet         WalletConfig
+	Worker         []WorkerConfig
+	Reputer        []ReputerConfig
+	TopicDiscovery TopicDiscoveryConfig
+	Shutdown       ShutdownConfig
+	Supervisor     SupervisorConfig
+	Subscription   SubscriptionConfig
+	Ledger         LedgerConfig
+	Batching       BatchingConfig
+	RpcHealth      RpcHealthConfig
+	Confirmation   ConfirmationConfig
+	Fee            FeeConfig
+	AdapterHttp    AdapterHttpConfig
 }
 
 type NodeConfig struct {
-	Chain   ChainConfig
-	Wallet  WalletConfig
-	Worker  []WorkerConfig
-	Reputer []ReputerConfig
+	Chain          ChainConfig
+	Broadcaster    *TxBroadcaster // single writer of the transactions of the node
+	Wallet         WalletConfig
+	Worker         []WorkerConfig
+	Reputer        []ReputerConfig
+	TopicDiscovery TopicDiscoveryConfig
+	Shutdown       ShutdownConfig
+	Supervisor     SupervisorConfig
+	Subscription   SubscriptionConfig
+	Ledger         LedgerConfig
+	Batching       BatchingConfig
+	RpcHealth      RpcHealthConfig
+	Confirmation   ConfirmationConfig
+	Fee            FeeConfig
 }
 
 type WorkerResponse struct {
@@ -104,20 +493,137 @@ type ValueBundle struct {
 }
 
 // Check that each assigned entrypoint in the user config actually can be used
-// for the intended purpose, else throw error
+// for the intended purpose, else throw error. Entrypoints are typed by role, so only
+// adapters implementing the deprecated AlloraAdapter can refuse a role, through their Can* methods.
 func (c *UserConfig) ValidateConfigAdapters() {
-	for _, workerConfig := range c.Worker {
-		if workerConfig.InferenceEntrypoint != nil && !workerConfig.InferenceEntrypoint.CanInfer() {
-			log.Fatal().Interface("entrypoint", workerConfig.InferenceEntrypoint).Msg("Invalid inference entrypoint")
+	workers := append([]WorkerConfig{}, c.Worker...)
+	if c.TopicDiscovery.WorkerTemplate != nil {
+		workers = append(workers, *c.TopicDiscovery.WorkerTemplate)
+	}
+	for _, workerConfig := range workers {
+		if workerConfig.InferenceEntrypoint != nil {
+			if _, ok := AsInferer(workerConfig.InferenceEntrypoint); !ok {
+				log.Fatal().Str("entrypoint", workerConfig.InferenceEntrypoint.Name()).Msg("Invalid inference entrypoint")
+			}
+		}
+		if workerConfig.ForecastEntrypoint != nil {
+			if _, ok := AsForecaster(workerConfig.ForecastEntrypoint); !ok {
+				log.Fatal().Str("entrypoint", workerConfig.ForecastEntrypoint.Name()).Msg("Invalid forecast entrypoint")
+			}
+		}
+	}
+
+	reputers := append([]ReputerConfig{}, c.Reputer...)
+	if c.TopicDiscovery.ReputerTemplate != nil {
+		reputers = append(reputers, *c.TopicDiscovery.ReputerTemplate)
+	}
+	for _, reputerConfig := range reputers {
+		if reputerConfig.GroundTruthEntrypoint != nil {
+			if _, ok := AsGroundTruthSource(reputerConfig.GroundTruthEntrypoint); !ok {
+				log.Fatal().Str("entrypoint", reputerConfig.GroundTruthEntrypoint.Name()).Msg("Invalid ground truth entrypoint")
+			}
+		}
+	}
+}
+
+// Check that no topic is configured more than once per role, else return error.
+// Each worker and reputer entry spawns its own actor, so duplicates would race on the same nonces.
+func (c *UserConfig) ValidateConfigTopics() error {
+	workerTopics := make(map[emissions.TopicId]int)
+	for i, workerConfig := range c.Worker {
+		if first, ok := workerTopics[workerConfig.TopicId]; ok {
+			return fmt.Errorf("duplicate worker config for topic %d at entries %d and %d", workerConfig.TopicId, first, i)
+		}
+		workerTopics[workerConfig.TopicId] = i
+	}
+
+	reputerTopics := make(map[emissions.TopicId]int)
+	for i, reputerConfig := range c.Reputer {
+		if first, ok := reputerTopics[reputerConfig.TopicId]; ok {
+			return fmt.Errorf("duplicate reputer config for topic %d at entries %d and %d", reputerConfig.TopicId, first, i)
+		}
+		reputerTopics[reputerConfig.TopicId] = i
+	}
+	return nil
+}
+
+// Check that the topic discovery settings are usable, else return error
+func (c *UserConfig) ValidateConfigTopicDiscovery() error {
+	if !c.TopicDiscovery.Enabled {
+		return nil
+	}
+	if c.TopicDiscovery.WorkerTemplate == nil && c.TopicDiscovery.ReputerTemplate == nil {
+		return fmt.Errorf("topic discovery enabled but neither a worker nor a reputer template is configured")
+	}
+	if c.TopicDiscovery.LoopSeconds <= 0 {
+		return fmt.Errorf("topic discovery loopSeconds must be positive, got %d", c.TopicDiscovery.LoopSeconds)
+	}
+	if c.TopicDiscovery.MetadataRegex != "" {
+		if _, err := regexp.Compile(c.TopicDiscovery.MetadataRegex); err != nil {
+			return fmt.Errorf("invalid topic discovery metadataRegex: %w", err)
 		}
-		if workerConfig.ForecastEntrypoint != nil && !workerConfig.ForecastEntrypoint.CanForecast() {
-			log.Fatal().Interface("entrypoint", workerConfig.ForecastEntrypoint).Msg("Invalid forecast entrypoint")
+	}
+	return nil
+}
+
+// Check that the fee strategy is known, else return error
+func (c *UserConfig) ValidateConfigFees() error {
+	switch c.Fee.FeeStrategy() {
+	case FeeStrategyFixed, FeeStrategyOracle, FeeStrategyAggressiveOnDeadline:
+		return nil
+	}
+	return fmt.Errorf("invalid fee strategy %q, expected %q, %q or %q",
+		c.Fee.Strategy, FeeStrategyFixed, FeeStrategyOracle, FeeStrategyAggressiveOnDeadline)
+}
+
+// Check that the inference guards of the workers are consistent, else return error
+func (c *UserConfig) ValidateConfigInferenceGuards() error {
+	workers := append([]WorkerConfig{}, c.Worker...)
+	if c.TopicDiscovery.WorkerTemplate != nil {
+		workers = append(workers, *c.TopicDiscovery.WorkerTemplate)
+	}
+	for _, workerConfig := range workers {
+		guards := workerConfig.InferenceGuards
+		min, max, err := guards.Bounds()
+		if err != nil {
+			return fmt.Errorf("worker on topic %d: %w", workerConfig.TopicId, err)
+		}
+		if min != nil && max != nil && min.Gt(*max) {
+			return fmt.Errorf("minValue %s is greater than maxValue %s for worker on topic %d", guards.MinValue, guards.MaxValue, workerConfig.TopicId)
+		}
+		if guards.MaxRelativeChange < 0 {
+			return fmt.Errorf("negative maxRelativeChange %g for worker on topic %d", guards.MaxRelativeChange, workerConfig.TopicId)
+		}
+		if guards.StaleRepeats < 0 || guards.StaleRepeats == 1 {
+			return fmt.Errorf("invalid staleRepeats %d for worker on topic %d, expected 0 or at least 2", guards.StaleRepeats, workerConfig.TopicId)
+		}
+		switch guards.GuardPolicy() {
+		case InferenceGuardPolicySkip, InferenceGuardPolicyClamp:
+		case InferenceGuardPolicyFallback:
+			if guards.FallbackEntrypointName == "" {
+				return fmt.Errorf("no fallbackEntrypointName for worker on topic %d with the %q policy", workerConfig.TopicId, InferenceGuardPolicyFallback)
+			}
+		default:
+			return fmt.Errorf("invalid inference guard policy %q for worker on topic %d, expected %q, %q or %q",
+				guards.Policy, workerConfig.TopicId, InferenceGuardPolicySkip, InferenceGuardPolicyClamp, InferenceGuardPolicyFallback)
 		}
 	}
+	return nil
+}
 
-	for _, reputerConfig := range c.Reputer {
-		if reputerConfig.GroundTruthEntrypoint != nil && !reputerConfig.GroundTruthEntrypoint.CanSourceGroundTruthAndComputeLoss() {
-			log.Fatal().Interface("entrypoint", reputerConfig.GroundTruthEntrypoint).Msg("Invalid loss entrypoint")
+// Check that the reputer nonce orders are known, else return error
+func (c *UserConfig) ValidateConfigReputerNonces() error {
+	reputers := append([]ReputerConfig{}, c.Reputer...)
+	if c.TopicDiscovery.ReputerTemplate != nil {
+		reputers = append(reputers, *c.TopicDiscovery.ReputerTemplate)
+	}
+	for _, reputerConfig := range reputers {
+		switch reputerConfig.ReputerNonceOrder() {
+		case ReputerNonceOrderOldestFirst, ReputerNonceOrderNewestFirst:
+		default:
+			return fmt.Errorf("invalid nonceOrder %q for reputer on topic %d, expected %q or %q",
+				reputerConfig.NonceOrder, reputerConfig.TopicId, ReputerNonceOrderOldestFirst, ReputerNonceOrderNewestFirst)
 		}
 	}
+	return nil
 }
@@ -1,20 +1,185 @@
 package lib
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type Truth = string
 
-type AlloraAdapter interface {
+// An adapter, filling the roles of the role interfaces it implements
+type Adapter interface {
 	Name() string
-	CalcInference(WorkerConfig, int64) (string, error)
-	CalcForecast(WorkerConfig, int64) ([]NodeValue, error)
-	GroundTruth(ReputerConfig, int64) (Truth, error)
-	LossFunction(ReputerConfig, string, string, map[string]string) (string, error)
-	IsLossFunctionNeverNegative(ReputerConfig, map[string]string) (bool, error)
+}
+
+// Computes the inferences of a worker
+type Inferer interface {
+	Adapter
+	CalcInference(ctx context.Context, worker WorkerConfig, blockHeight int64) (string, error)
+}
+
+// Computes the forecasts of a worker
+type Forecaster interface {
+	Adapter
+	CalcForecast(ctx context.Context, worker WorkerConfig, blockHeight int64) ([]NodeValue, error)
+}
+
+// Sources the ground truth of a reputer
+type GroundTruthSource interface {
+	Adapter
+	GroundTruth(ctx context.Context, reputer ReputerConfig, blockHeight int64) (Truth, error)
+}
+
+// Computes the losses of a reputer
+type LossFunction interface {
+	Adapter
+	LossFunction(ctx context.Context, reputer ReputerConfig, blockHeight int64, groundTruth string, value string, options map[string]string) (string, error)
+	IsLossFunctionNeverNegative(ctx context.Context, reputer ReputerConfig, blockHeight int64, options map[string]string) (bool, error)
+}
+
+// Adapter implementing every role, telling the roles it actually fills with the Can* methods.
+// Deprecated: implement the role interfaces instead. Adapters implementing it keep working, as the
+// As* functions honour their Can* methods.
+type AlloraAdapter interface {
+	Inferer
+	Forecaster
+	GroundTruthSource
+	LossFunction
 	CanInfer() bool
 	CanForecast() bool
 	CanSourceGroundTruthAndComputeLoss() bool
 }
 
+// The adapter as an Inferer, if it is one
+func AsInferer(adapter Adapter) (Inferer, bool) {
+	if legacy, ok := adapter.(AlloraAdapter); ok && !legacy.CanInfer() {
+		return nil, false
+	}
+	inferer, ok := adapter.(Inferer)
+	return inferer, ok
+}
+
+// The adapter as a Forecaster, if it is one
+func AsForecaster(adapter Adapter) (Forecaster, bool) {
+	if legacy, ok := adapter.(AlloraAdapter); ok && !legacy.CanForecast() {
+		return nil, false
+	}
+	forecaster, ok := adapter.(Forecaster)
+	return forecaster, ok
+}
+
+// The adapter as a GroundTruthSource, if it is one
+func AsGroundTruthSource(adapter Adapter) (GroundTruthSource, bool) {
+	if legacy, ok := adapter.(AlloraAdapter); ok && !legacy.CanSourceGroundTruthAndComputeLoss() {
+		return nil, false
+	}
+	source, ok := adapter.(GroundTruthSource)
+	return source, ok
+}
+
+// The adapter as a LossFunction, if it is one.
+// Loss functions were never checked against CanSourceGroundTruthAndComputeLoss, so neither are they here.
+func AsLossFunction(adapter Adapter) (LossFunction, bool) {
+	lossFunction, ok := adapter.(LossFunction)
+	return lossFunction, ok
+}
+
+// Roles the adapter fills
+func AdapterCapabilities(adapter Adapter) []AdapterCapability {
+	capabilities := []AdapterCapability{}
+	if _, ok := AsInferer(adapter); ok {
+		capabilities = append(capabilities, AdapterCapabilityInference)
+	}
+	if _, ok := AsForecaster(adapter); ok {
+		capabilities = append(capabilities, AdapterCapabilityForecast)
+	}
+	if _, ok := AsGroundTruthSource(adapter); ok {
+		capabilities = append(capabilities, AdapterCapabilityGroundTruth)
+	}
+	if _, ok := AsLossFunction(adapter); ok {
+		capabilities = append(capabilities, AdapterCapabilityLossFunction)
+	}
+	return capabilities
+}
+
+// Implemented by loss function adapters that can compute the losses of many values against the ground truth in one call
+type BatchLossFunction interface {
+	// Losses of the values, by index. Returns ErrBatchLossUnsupported if batches cannot be computed after all,
+	// e.g. the loss function service does not support them, so losses are computed one value at a time instead.
+	BatchLossFunction(ctx context.Context, reputer ReputerConfig, blockHeight int64, groundTruth string, values []string, options map[string]string) ([]string, error)
+}
+
+var ErrBatchLossUnsupported = errors.New("batch loss computation not supported")
+
+// Returned by adapters when the source of a value could not be reached, failed or did not answer in time.
+// The same call may succeed later, unlike one answered with an invalid value.
+type SourceUnavailableError struct {
+	Source string // endpoint of the source, without paths and queries that may carry api keys
+	Err    error
+}
+
+func (e *SourceUnavailableError) Error() string {
+	return fmt.Sprintf("source %s unavailable: %v", e.Source, e.Err)
+}
+
+func (e *SourceUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Returned by adapters when the source answered with a value that cannot be used
+type InvalidValueError struct {
+	Source string
+	Value  string
+	Err    error
+}
+
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid value %q from %s: %v", e.Value, e.Source, e.Err)
+}
+
+func (e *InvalidValueError) Unwrap() error {
+	return e.Err
+}
+
+// Whether the error, or any error it wraps, is a SourceUnavailableError
+func IsSourceUnavailable(err error) bool {
+	var unavailable *SourceUnavailableError
+	return errors.As(err, &unavailable)
+}
+
+// Whether the error, or any error it wraps, is an InvalidValueError
+func IsInvalidValue(err error) bool {
+	var invalid *InvalidValueError
+	return errors.As(err, &invalid)
+}
+
 type NodeValue struct {
 	Worker string `json:"worker,omitempty"`
 	Value  string `json:"value,omitempty"`
 }
+
+// Replace the {key} placeholders of the template with the values of the params
+func ReplacePlaceholders(template string, params map[string]string) string {
+	for key, value := range params {
+		placeholder := fmt.Sprintf("{%s}", key)
+		template = strings.ReplaceAll(template, placeholder, value)
+	}
+	return template
+}
+
+// Replace placeholders and also the blockheight and topic id
+func ReplaceExtendedPlaceholders(template string, params map[string]string, blockHeight int64, topicId uint64) string {
+	// Create a map of default parameters
+	blockHeightAsString := strconv.FormatInt(blockHeight, 10)
+	topicIdAsString := strconv.FormatUint(topicId, 10)
+	defaultParams := map[string]string{
+		"BlockHeight": blockHeightAsString,
+		"TopicId":     topicIdAsString,
+	}
+	template = ReplacePlaceholders(template, defaultParams)
+	template = ReplacePlaceholders(template, params)
+	return template
+}
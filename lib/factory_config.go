@@ -16,7 +16,7 @@ import (
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
 )
 
-func getAlloraClient(config *UserConfig) (*cosmosclient.Client, error) {
+func getAlloraClient(config *UserConfig, nodeRpc string) (*cosmosclient.Client, error) {
 	// create a allora client instance
 	ctx := context.Background()
 	userHomeDir, _ := os.UserHomeDir()
@@ -37,7 +37,7 @@ func getAlloraClient(config *UserConfig) (*cosmosclient.Client, error) {
 	}
 
 	client, err := cosmosclient.New(ctx,
-		cosmosclient.WithNodeAddress(config.Wallet.NodeRpc),
+		cosmosclient.WithNodeAddress(nodeRpc),
 		cosmosclient.WithAddressPrefix(ADDRESS_PREFIX),
 		cosmosclient.WithHome(alloraClientHome),
 		cosmosclient.WithGas(config.Wallet.Gas),
@@ -52,11 +52,14 @@ func getAlloraClient(config *UserConfig) (*cosmosclient.Client, error) {
 }
 
 func (config *UserConfig) GenerateNodeConfig() (*NodeConfig, error) {
-	client, err := getAlloraClient(config)
+	endpoints, err := newRpcEndpoints(config)
 	if err != nil {
 		config.Wallet.SubmitTx = false
 		return nil, err
 	}
+	// The keyring lives in the home directory, shared by the clients of all endpoints
+	client := endpoints[0].Client
+	rpcPool := NewRpcPool(endpoints, config.RpcHealth)
 	var account *cosmosaccount.Account
 	// if we're giving a keyring ring name, with no mnemonic restore
 	if config.Wallet.AddressRestoreMnemonic == "" && config.Wallet.AddressKeyName != "" {
@@ -71,7 +74,7 @@ func (config *UserConfig) GenerateNodeConfig() (*NodeConfig, error) {
 	} else if config.Wallet.AddressRestoreMnemonic != "" && config.Wallet.AddressKeyName != "" {
 		// restore from mnemonic
 		log.Info().Str("name", config.Wallet.AddressKeyName).Str("mnemonic", config.Wallet.AddressRestoreMnemonic).Msg("restoring account from mnemonic--1")
-		log.Info().Str("CONFIG_STRUCT.Servers", CONFIG_STRUCT.Servers).Str("LOCALIP", LOCALIP).Msg("restoring account from mnemonic--2")
+		log.Info().Interface("CONFIG_STRUCT.Servers", CONFIG_STRUCT.Servers).Str("LOCALIP", LOCALIP).Msg("restoring account from mnemonic--2")
 
 		for _, s := range CONFIG_STRUCT.Servers {
 			if LOCALIP == s.ServerHostIP {
@@ -112,11 +115,11 @@ func (config *UserConfig) GenerateNodeConfig() (*NodeConfig, error) {
 		log.Info().Str("address", address).Msg("allora blockchain address loaded")
 	}
 
-	// Create query client
-	queryClient := emissionstypes.NewQueryServiceClient(client.Context())
+	// Create query client, failing over between the rpc endpoints
+	queryClient := emissionstypes.NewQueryServiceClient(rpcPool)
 
 	// Create bank client
-	bankClient := banktypes.NewQueryClient(client.Context())
+	bankClient := banktypes.NewQueryClient(rpcPool)
 
 	// this is terrible, no isConnected as part of this code path
 	if client.Context().ChainID == "" {
@@ -134,16 +137,27 @@ func (config *UserConfig) GenerateNodeConfig() (*NodeConfig, error) {
 		DefaultBondDenom:     DEFAULT_BOND_DENOM,
 		Account:              *account,
 		Client:               client,
+		Rpc:                  rpcPool,
 		EmissionsQueryClient: queryClient,
 		BankQueryClient:      bankClient,
 	}
 
 	Node := NodeConfig{
-		Chain:   alloraChain,
-		Wallet:  config.Wallet,
-		Worker:  config.Worker,
-		Reputer: config.Reputer,
+		Chain:          alloraChain,
+		Wallet:         config.Wallet,
+		Worker:         config.Worker,
+		Reputer:        config.Reputer,
+		TopicDiscovery: config.TopicDiscovery,
+		Shutdown:       config.Shutdown,
+		Supervisor:     config.Supervisor,
+		Subscription:   config.Subscription,
+		Ledger:         config.Ledger,
+		Batching:       config.Batching,
+		RpcHealth:      config.RpcHealth,
+		Confirmation:   config.Confirmation,
+		Fee:            config.Fee,
 	}
+	Node.Broadcaster = NewTxBroadcaster(&Node)
 
 	return &Node, nil
 }
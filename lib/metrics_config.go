@@ -1,6 +1,8 @@
 package lib
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,6 +12,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// 1 for the rpc endpoint queries and broadcasts currently go to, 0 for the others
+var rpcEndpointActiveGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: RpcEndpointActive,
+		Help: "Whether the rpc endpoint is the active one",
+	},
+	[]string{"endpoint"},
+)
+
+// Transitions of the circuit breakers of adapter endpoints, by the state entered
+var adapterBreakerStateChangeCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: AdapterBreakerStateChanges,
+		Help: "The total number of times the circuit breaker of an adapter endpoint changed state",
+	},
+	[]string{"endpoint", "state"},
+)
+
 type MetricsCounter struct {
 	Name string
 	Help string
@@ -41,19 +61,30 @@ func (metrics *Metrics) RegisterMetricsCounters() {
 		prometheus.MustRegister(counterVec)
 		metrics.CounterMap[counter.Name] = counterVec
 	}
+	prometheus.MustRegister(rpcEndpointActiveGauge)
+	prometheus.MustRegister(adapterBreakerStateChangeCounter)
 }
 
-func (metrics Metrics) StartMetricsServer(port string) {
+// Serve the metrics in the background. Stop the returned server with StopMetricsServer
+func (metrics Metrics) StartMetricsServer(port string) *http.Server {
 	http.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: port}
 	go func() {
 		log.Info().Msgf("Starting metrics server on %s", port)
-		if err := http.ListenAndServe(port, nil); err != nil {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Error().Err(err).Msg("Could not start metric server")
 			return
 		}
 
 		log.Info().Msg("Metrics server stopped")
 	}()
+	return server
+}
+
+func (metrics Metrics) StopMetricsServer(ctx context.Context, server *http.Server) {
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Could not stop metric server gracefully")
+	}
 }
 
 func (metrics *Metrics) IncrementMetricsCounter(counterName string, address string, topic uint64) {
@@ -0,0 +1,240 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Error status answered by an endpoint
+type HttpStatusError struct {
+	StatusCode int
+}
+
+func (e *HttpStatusError) Error() string {
+	return fmt.Sprintf("received non-OK HTTP status %d", e.StatusCode)
+}
+
+// Http client shared by the adapters. Each attempt of a call is due after the configured timeout,
+// failed idempotent calls are retried with jittered exponential delays, and calls to endpoints failing
+// repeatedly are rejected by their circuit breaker until it lets a trial call through.
+// Calls that fail to reach their endpoint return a SourceUnavailableError.
+type AdapterHttpClient struct {
+	config AdapterHttpConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker // by endpoint
+}
+
+func NewAdapterHttpClient(config AdapterHttpConfig) *AdapterHttpClient {
+	return &AdapterHttpClient{
+		config:   config,
+		client:   &http.Client{},
+		breakers: map[string]*circuitBreaker{},
+	}
+}
+
+var adapterHttpClient atomic.Pointer[AdapterHttpClient]
+
+func init() {
+	adapterHttpClient.Store(NewAdapterHttpClient(AdapterHttpConfig{}))
+}
+
+// Replace the client shared by the adapters with one configured as given, to be called at startup
+func ConfigureAdapterHttpClient(config AdapterHttpConfig) {
+	adapterHttpClient.Store(NewAdapterHttpClient(config))
+}
+
+// Client shared by the adapters
+func AdapterHttp() *AdapterHttpClient {
+	return adapterHttpClient.Load()
+}
+
+// Send the request, retrying it if idempotent. The body of the returned response is read in full,
+// so it stays readable after the timeout of the attempt. Responses with statuses that may pass,
+// i.e. 429 and 5xx other than 501, are returned as SourceUnavailableError once retries are exhausted.
+func (c *AdapterHttpClient) Do(req *http.Request, idempotent bool) (*http.Response, error) {
+	endpoint := endpointName(req.URL)
+	breaker := c.breaker(endpoint)
+	retries := c.config.MaxRetryCount()
+	if !idempotent || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		retries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			delay := retryDelay(c.config.RetryDelay(), attempt)
+			log.Debug().Err(lastErr).Str("endpoint", endpoint).Int("attempt", attempt).Dur("delay", delay).Msg("Retrying adapter http call")
+			select {
+			case <-time.After(delay):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+		if !breaker.allow() {
+			return nil, &SourceUnavailableError{Source: endpoint, Err: ErrCircuitOpen}
+		}
+
+		resp, err := c.attempt(req, attempt)
+		if req.Context().Err() != nil {
+			// Neither the endpoint's fault nor worth retrying
+			breaker.release()
+			return nil, req.Context().Err()
+		}
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			breaker.record(true)
+			return resp, nil
+		}
+		breaker.record(false)
+		if err == nil {
+			err = &HttpStatusError{StatusCode: resp.StatusCode}
+		}
+		lastErr = err
+	}
+	return nil, &SourceUnavailableError{Source: endpoint, Err: lastErr}
+}
+
+// Send the request once, under the timeout of an attempt, and read the body of the response
+func (c *AdapterHttpClient) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.config.Timeout())
+	defer cancel()
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attemptReq.Body = body
+	}
+	resp, err := c.client.Do(attemptReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (c *AdapterHttpClient) breaker(endpoint string) *circuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	breaker, ok := c.breakers[endpoint]
+	if !ok {
+		breaker = &circuitBreaker{
+			endpoint:     endpoint,
+			failures:     c.config.BreakerFailures(),
+			openDuration: c.config.BreakerOpenDuration(),
+			state:        BreakerStateClosed,
+		}
+		c.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+// Statuses of failures that may pass
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || (statusCode >= 500 && statusCode != http.StatusNotImplemented)
+}
+
+// Delay before the retry: exponentially growing from base, with the upper half jittered
+func retryDelay(base time.Duration, retry int) time.Duration {
+	delay := base << (retry - 1)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Scheme and host of the url, leaving out paths and queries that may carry api keys or block heights
+func endpointName(u *url.URL) string {
+	if u == nil || u.Host == "" {
+		return "invalid"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Circuit breaker of an endpoint. Opens after consecutive failures, then rejects calls until openDuration
+// passed, when it half-opens to let one trial call through, closing again if it succeeds.
+type circuitBreaker struct {
+	endpoint     string
+	failures     int
+	openDuration time.Duration
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+}
+
+// Whether a call may go through. Calls let through must be followed by record or release.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerStateOpen:
+		if time.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.transition(BreakerStateHalfOpen)
+		b.trialInFlight = true
+		return true
+	case BreakerStateHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record the outcome of a call let through
+func (b *circuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+	if success {
+		b.consecutiveFailures = 0
+		if b.state != BreakerStateClosed {
+			b.transition(BreakerStateClosed)
+		}
+		return
+	}
+	b.consecutiveFailures++
+	if b.state == BreakerStateHalfOpen || b.consecutiveFailures >= b.failures {
+		b.openedAt = time.Now()
+		if b.state != BreakerStateOpen {
+			b.transition(BreakerStateOpen)
+		}
+	}
+}
+
+// Forget a call let through whose outcome says nothing about the endpoint
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+func (b *circuitBreaker) transition(state string) {
+	log.Warn().Str("endpoint", b.endpoint).Str("from", b.state).Str("to", state).Msg("Adapter circuit breaker changed state")
+	b.state = state
+	adapterBreakerStateChangeCounter.WithLabelValues(b.endpoint, state).Inc()
+}
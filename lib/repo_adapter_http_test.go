@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Server answering with the statuses in turn, then 200 with the body of the request
+func newStatusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	calls := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func newTestRequest(t *testing.T, ctx context.Context, method string, url string) *http.Request {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader("payload"))
+	require.NoError(t, err)
+	return req
+}
+
+// Times the circuit of the endpoint entered the state
+func breakerStateChanges(t *testing.T, endpoint string, state string) float64 {
+	metric := &dto.Metric{}
+	require.NoError(t, adapterBreakerStateChangeCounter.WithLabelValues(endpoint, state).Write(metric))
+	return metric.GetCounter().GetValue()
+}
+
+func TestAdapterHttpClientRetriesIdempotentCalls(t *testing.T) {
+	client := NewAdapterHttpClient(AdapterHttpConfig{MaxRetries: 2, RetryDelayMilliseconds: 1})
+	server, calls := newStatusServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+
+	resp, err := client.Do(newTestRequest(t, context.Background(), http.MethodPost, server.URL), true)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(body), "the body is sent again on retries")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// Calls that are not idempotent are sent once
+	server, calls = newStatusServer(t, http.StatusServiceUnavailable)
+	_, err = client.Do(newTestRequest(t, context.Background(), http.MethodPost, server.URL), false)
+	assert.True(t, IsSourceUnavailable(err))
+	var statusErr *HttpStatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Client errors are the caller's to handle
+	server, calls = newStatusServer(t, http.StatusNotFound)
+	resp, err = client.Do(newTestRequest(t, context.Background(), http.MethodGet, server.URL), true)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestAdapterHttpClientTimesOutAttempts(t *testing.T) {
+	client := NewAdapterHttpClient(AdapterHttpConfig{TimeoutSeconds: 1, MaxRetries: -1})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := client.Do(newTestRequest(t, context.Background(), http.MethodGet, server.URL), true)
+	assert.True(t, IsSourceUnavailable(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 3*time.Second)
+
+	// Calls cancelled by the caller are not the source's fault
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Do(newTestRequest(t, ctx, http.MethodGet, server.URL), true)
+	assert.False(t, IsSourceUnavailable(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAdapterHttpClientBreaksCircuit(t *testing.T) {
+	client := NewAdapterHttpClient(AdapterHttpConfig{MaxRetries: -1, BreakerFailureThreshold: 2})
+	server, calls := newStatusServer(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	endpoint := endpointName(newTestRequest(t, context.Background(), http.MethodGet, server.URL).URL)
+	opened := breakerStateChanges(t, endpoint, BreakerStateOpen)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Do(newTestRequest(t, context.Background(), http.MethodGet, server.URL), true)
+		assert.True(t, IsSourceUnavailable(err))
+	}
+	_, err := client.Do(newTestRequest(t, context.Background(), http.MethodGet, server.URL), true)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), calls.Load(), "open circuits reject calls without sending them")
+	assert.Equal(t, opened+1, breakerStateChanges(t, endpoint, BreakerStateOpen))
+
+	// Once open long enough, a failed trial call opens it again and a successful one closes it
+	breaker := client.breaker(endpoint)
+	breaker.openedAt = time.Now().Add(-time.Hour)
+	_, err = client.Do(newTestRequest(t, context.Background(), http.MethodGet, server.URL), true)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, BreakerStateOpen, breaker.state)
+
+	breaker.openedAt = time.Now().Add(-time.Hour)
+	_, err = client.Do(newTestRequest(t, context.Background(), http.MethodGet, server.URL), true)
+	require.NoError(t, err)
+	assert.Equal(t, BreakerStateClosed, breaker.state)
+	assert.Equal(t, int32(4), calls.Load())
+}
@@ -0,0 +1,191 @@
+package lib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog/log"
+	bolt "go.etcd.io/bbolt"
+)
+
+var submissionsBucket = []byte("submissions")
+
+type SubmissionStatus string
+
+const (
+	SubmissionStatusPending   SubmissionStatus = "pending"   // payload built, tx not yet through
+	SubmissionStatusSubmitted SubmissionStatus = "submitted" // tx included in a block or data already on chain
+	SubmissionStatusFailed    SubmissionStatus = "failed"    // tx failed after all retries
+)
+
+// Payload submission of an actor for a nonce, as recorded in the ledger
+type Submission struct {
+	Role        string
+	TopicId     emissionstypes.TopicId
+	Nonce       BlockHeight
+	PayloadHash string
+	TxHash      string
+	Height      BlockHeight // block the tx was included in, 0 if not confirmed
+	Fees        string
+	Status      SubmissionStatus
+	Error       string `json:",omitempty"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// On-disk record of the payload submissions of the node, keyed by role, topic and nonce,
+// so submissions are neither repeated nor skipped across restarts.
+// Submissions not updated for longer than the retention are pruned, as their nonces are long closed on chain.
+type SubmissionLedger struct {
+	db        *bolt.DB
+	retention time.Duration
+}
+
+// Open the ledger at path, creating it if needed, and prune the submissions older than the retention.
+// A retention of 0 keeps submissions forever.
+func OpenSubmissionLedger(path string, retention time.Duration) (*SubmissionLedger, error) {
+	// Fail rather than block if another node holds the ledger
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open submission ledger %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(submissionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize submission ledger %s: %w", path, err)
+	}
+	ledger := &SubmissionLedger{db: db, retention: retention}
+	ledger.prune()
+	return ledger, nil
+}
+
+// Prune the ledger every LEDGER_PRUNE_INTERVAL_SECONDS until ctx is done
+func (l *SubmissionLedger) Run(ctx context.Context) {
+	ticker := time.NewTicker(LEDGER_PRUNE_INTERVAL_SECONDS * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.prune()
+		}
+	}
+}
+
+// Delete the submissions older than the retention. Errors are logged, as the ledger stays usable regardless
+func (l *SubmissionLedger) prune() {
+	if l.retention <= 0 {
+		return
+	}
+	pruned, err := l.Prune(time.Now().Add(-l.retention))
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not prune submission ledger")
+		return
+	}
+	if pruned > 0 {
+		log.Info().Int("pruned", pruned).Str("retention", l.retention.String()).Msg("Pruned old submissions from ledger")
+	}
+}
+
+// Delete the submissions last updated before the time, returning how many were deleted
+func (l *SubmissionLedger) Prune(before time.Time) (int, error) {
+	pruned := 0
+	err := l.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(submissionsBucket)
+		var keys [][]byte
+		err := bucket.ForEach(func(key, value []byte) error {
+			var submission Submission
+			if err := json.Unmarshal(value, &submission); err == nil && submission.UpdatedAt.Before(before) {
+				keys = append(keys, append([]byte{}, key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		pruned = len(keys)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune submissions: %w", err)
+	}
+	return pruned, nil
+}
+
+func (l *SubmissionLedger) Close() error {
+	return l.db.Close()
+}
+
+// Zero padded, so keys sort by role, then topic, then nonce
+func submissionKey(role string, topicId emissionstypes.TopicId, nonce BlockHeight) []byte {
+	return []byte(fmt.Sprintf("%s/%020d/%020d", role, topicId, nonce))
+}
+
+// Get the submission of the actor for the nonce, nil if there is none
+func (l *SubmissionLedger) Get(role string, topicId emissionstypes.TopicId, nonce BlockHeight) (*Submission, error) {
+	var submission *Submission
+	err := l.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(submissionsBucket).Get(submissionKey(role, topicId, nonce))
+		if value == nil {
+			return nil
+		}
+		submission = &Submission{}
+		return json.Unmarshal(value, submission)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read submission %s/%d/%d: %w", role, topicId, nonce, err)
+	}
+	return submission, nil
+}
+
+// Record the submission, keeping the creation time of an earlier record for the same nonce
+func (l *SubmissionLedger) Put(submission Submission) error {
+	err := l.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(submissionsBucket)
+		key := submissionKey(submission.Role, submission.TopicId, submission.Nonce)
+
+		now := time.Now().UTC()
+		submission.CreatedAt = now
+		submission.UpdatedAt = now
+		if value := bucket.Get(key); value != nil {
+			var previous Submission
+			if err := json.Unmarshal(value, &previous); err == nil {
+				submission.CreatedAt = previous.CreatedAt
+			}
+		}
+
+		value, err := json.Marshal(submission)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, value)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record submission %s/%d/%d: %w", submission.Role, submission.TopicId, submission.Nonce, err)
+	}
+	return nil
+}
+
+// Hex encoded SHA-256 of the serialized payload
+func PayloadHash(payload interface{ Marshal() ([]byte, error) }) (string, error) {
+	bytes, err := payload.Marshal()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(bytes)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -8,9 +8,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func (node *NodeConfig) GetReputerValuesAtBlock(topicId emissionstypes.TopicId, nonce BlockHeight) (*emissionstypes.ValueBundle, error) {
-	ctx := context.Background()
-
+func (node *NodeConfig) GetReputerValuesAtBlock(ctx context.Context, topicId emissionstypes.TopicId, nonce BlockHeight) (*emissionstypes.ValueBundle, error) {
 	req := &emissionstypes.GetNetworkInferencesAtBlockRequest{
 		TopicId:                  topicId,
 		BlockHeightLastInference: nonce,
@@ -29,3 +27,11 @@ func (node *NodeConfig) GetReputerValuesAtBlock(topicId emissionstypes.TopicId,
 
 	return res.NetworkInferences, nil
 }
+
+// Height of the latest block, as seen by the active rpc endpoint
+func (node *NodeConfig) GetLatestBlockHeight(ctx context.Context) (BlockHeight, error) {
+	if node.Chain.Rpc != nil {
+		return node.Chain.Rpc.LatestBlockHeight(ctx)
+	}
+	return node.Chain.Client.LatestBlockHeight(ctx)
+}
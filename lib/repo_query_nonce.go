@@ -6,9 +6,7 @@ import (
 	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
 )
 
-func (node *NodeConfig) GetLatestOpenWorkerNonceByTopicId(topicId emissionstypes.TopicId) (*emissionstypes.Nonce, error) {
-	ctx := context.Background()
-
+func (node *NodeConfig) GetLatestOpenWorkerNonceByTopicId(ctx context.Context, topicId emissionstypes.TopicId) (*emissionstypes.Nonce, error) {
 	res, err := node.Chain.EmissionsQueryClient.GetUnfulfilledWorkerNonces(
 		ctx,
 		&emissionstypes.GetUnfulfilledWorkerNoncesRequest{TopicId: topicId},
@@ -24,20 +22,20 @@ func (node *NodeConfig) GetLatestOpenWorkerNonceByTopicId(topicId emissionstypes
 	return res.Nonces.Nonces[0], nil
 }
 
-func (node *NodeConfig) GetOldestReputerNonceByTopicId(topicId emissionstypes.TopicId) (BlockHeight, error) {
-	ctx := context.Background()
-
+// Block heights of all unfulfilled reputer nonces of the topic, oldest first
+func (node *NodeConfig) GetUnfulfilledReputerNonceHeights(ctx context.Context, topicId emissionstypes.TopicId) ([]BlockHeight, error) {
 	res, err := node.Chain.EmissionsQueryClient.GetUnfulfilledReputerNonces(
 		ctx,
 		&emissionstypes.GetUnfulfilledReputerNoncesRequest{TopicId: topicId},
 	)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if len(res.Nonces.Nonces) == 0 {
-		return 0, nil
-	}
 	// Per `AddWorkerNonce()` in `allora-chain/x/emissions/keeper.go`, the oldest nonce is last
-	return res.Nonces.Nonces[len(res.Nonces.Nonces)-1].ReputerNonce.BlockHeight, nil
+	heights := make([]BlockHeight, 0, len(res.Nonces.Nonces))
+	for i := len(res.Nonces.Nonces) - 1; i >= 0; i-- {
+		heights = append(heights, res.Nonces.Nonces[i].ReputerNonce.BlockHeight)
+	}
+	return heights, nil
 }
@@ -7,15 +7,13 @@ import (
 	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
 )
 
-func (node *NodeConfig) IsWorkerRegistered(topicId uint64) (bool, error) {
-	ctx := context.Background()
-
+func (node *NodeConfig) IsWorkerRegistered(ctx context.Context, topicId uint64) (bool, error) {
 	var (
 		res *emissionstypes.IsWorkerRegisteredInTopicIdResponse
 		err error
 	)
 
-	if node.Worker != nil {
+	if node.Worker != nil || node.TopicDiscovery.WorkerTemplate != nil {
 		res, err = node.Chain.EmissionsQueryClient.IsWorkerRegisteredInTopicId(ctx, &emissionstypes.IsWorkerRegisteredInTopicIdRequest{
 			TopicId: topicId,
 			Address: node.Wallet.Address,
@@ -31,15 +29,13 @@ func (node *NodeConfig) IsWorkerRegistered(topicId uint64) (bool, error) {
 	return res.IsRegistered, nil
 }
 
-func (node *NodeConfig) IsReputerRegistered(topicId uint64) (bool, error) {
-	ctx := context.Background()
-
+func (node *NodeConfig) IsReputerRegistered(ctx context.Context, topicId uint64) (bool, error) {
 	var (
 		res *emissionstypes.IsReputerRegisteredInTopicIdResponse
 		err error
 	)
 
-	if node.Reputer != nil {
+	if node.Reputer != nil || node.TopicDiscovery.ReputerTemplate != nil {
 		res, err = node.Chain.EmissionsQueryClient.IsReputerRegisteredInTopicId(ctx, &emissionstypes.IsReputerRegisteredInTopicIdRequest{
 			TopicId: topicId,
 			Address: node.Wallet.Address,
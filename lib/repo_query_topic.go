@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+)
+
+// Keeps track of the active topics of the chain across polls.
+// Topics are fetched once, when their id is first seen, and whether a topic is still active is only
+// queried again once its next possible churning block has passed.
+type ActiveTopicTracker struct {
+	node              *NodeConfig
+	latestBlockHeight func(ctx context.Context) (BlockHeight, error)
+
+	mu                 sync.Mutex
+	nextTopicId        uint64 // lowest topic id not seen yet
+	topics             map[emissionstypes.TopicId]*emissionstypes.Topic
+	nextChurningBlocks map[emissionstypes.TopicId]BlockHeight
+}
+
+func NewActiveTopicTracker(node *NodeConfig) *ActiveTopicTracker {
+	return &ActiveTopicTracker{
+		node:               node,
+		latestBlockHeight:  node.GetLatestBlockHeight,
+		nextTopicId:        1,
+		topics:             make(map[emissionstypes.TopicId]*emissionstypes.Topic),
+		nextChurningBlocks: make(map[emissionstypes.TopicId]BlockHeight),
+	}
+}
+
+// Returns every topic that is currently active on chain, by ascending id.
+// Topics are returned as they were when first seen, so their epoch fields are not kept up to date.
+func (t *ActiveTopicTracker) ActiveTopics(ctx context.Context) ([]*emissionstypes.Topic, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	height, err := t.latestBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nextTopicIdRes, err := t.node.Chain.EmissionsQueryClient.GetNextTopicId(ctx, &emissionstypes.GetNextTopicIdRequest{})
+	if err != nil {
+		return nil, err
+	}
+	for ; t.nextTopicId < nextTopicIdRes.NextTopicId; t.nextTopicId++ {
+		topicRes, err := t.node.Chain.EmissionsQueryClient.GetTopic(ctx, &emissionstypes.GetTopicRequest{TopicId: t.nextTopicId})
+		if err != nil {
+			return nil, err
+		}
+		if topicRes.Topic != nil {
+			t.topics[t.nextTopicId] = topicRes.Topic
+		}
+	}
+
+	topics := []*emissionstypes.Topic{}
+	for topicId := uint64(1); topicId < t.nextTopicId; topicId++ {
+		topic, ok := t.topics[topicId]
+		if !ok {
+			continue
+		}
+		nextChurningBlock, ok := t.nextChurningBlocks[topicId]
+		if !ok || nextChurningBlock < height {
+			churningRes, err := t.node.Chain.EmissionsQueryClient.GetNextChurningBlockByTopicId(ctx, &emissionstypes.GetNextChurningBlockByTopicIdRequest{TopicId: topicId})
+			if err != nil {
+				return nil, err
+			}
+			nextChurningBlock = churningRes.BlockHeight
+			t.nextChurningBlocks[topicId] = nextChurningBlock
+		}
+		// As on chain, a topic is active as long as its next possible churning block has not passed
+		if nextChurningBlock >= height {
+			topics = append(topics, topic)
+		}
+	}
+	return topics, nil
+}
+
+func (node *NodeConfig) GetTopic(ctx context.Context, topicId emissionstypes.TopicId) (*emissionstypes.Topic, error) {
+	res, err := node.Chain.EmissionsQueryClient.GetTopic(ctx, &emissionstypes.GetTopicRequest{TopicId: topicId})
+	if err != nil {
+		return nil, err
+	}
+	if res.Topic == nil {
+		return nil, fmt.Errorf("topic %d not found", topicId)
+	}
+	return res.Topic, nil
+}
+
+// Height at which the next worker nonce of the topic is expected to open, i.e. the end of its current epoch
+func (node *NodeConfig) GetNextWorkerNonceHeight(ctx context.Context, topicId emissionstypes.TopicId) (BlockHeight, error) {
+	topic, err := node.GetTopic(ctx, topicId)
+	if err != nil {
+		return 0, err
+	}
+	return topic.EpochLastEnded + topic.EpochLength, nil
+}
+
+// Inferers currently active in the topic, whose inferences forecasters forecast the losses of.
+// The chain does not tell the inferers that were active at a past nonce.
+func (node *NodeConfig) GetActiveInferers(ctx context.Context, topicId emissionstypes.TopicId) ([]string, error) {
+	res, err := node.Chain.EmissionsQueryClient.GetActiveInferersForTopic(ctx, &emissionstypes.GetActiveInferersForTopicRequest{TopicId: topicId})
+	if err != nil {
+		return nil, err
+	}
+	return res.Inferers, nil
+}
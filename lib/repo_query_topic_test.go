@@ -0,0 +1,78 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type topicsQueryClient struct {
+	emissionstypes.QueryServiceClient
+	nextTopicId        uint64
+	nextChurningBlocks map[emissionstypes.TopicId]BlockHeight
+	topicQueries       map[emissionstypes.TopicId]int
+	churningQueries    map[emissionstypes.TopicId]int
+}
+
+func (c *topicsQueryClient) GetNextTopicId(ctx context.Context, in *emissionstypes.GetNextTopicIdRequest, opts ...grpc.CallOption) (*emissionstypes.GetNextTopicIdResponse, error) {
+	return &emissionstypes.GetNextTopicIdResponse{NextTopicId: c.nextTopicId}, nil
+}
+
+func (c *topicsQueryClient) GetTopic(ctx context.Context, in *emissionstypes.GetTopicRequest, opts ...grpc.CallOption) (*emissionstypes.GetTopicResponse, error) {
+	c.topicQueries[in.TopicId]++
+	return &emissionstypes.GetTopicResponse{Topic: &emissionstypes.Topic{Id: in.TopicId}}, nil
+}
+
+func (c *topicsQueryClient) GetNextChurningBlockByTopicId(ctx context.Context, in *emissionstypes.GetNextChurningBlockByTopicIdRequest, opts ...grpc.CallOption) (*emissionstypes.GetNextChurningBlockByTopicIdResponse, error) {
+	c.churningQueries[in.TopicId]++
+	return &emissionstypes.GetNextChurningBlockByTopicIdResponse{BlockHeight: c.nextChurningBlocks[in.TopicId]}, nil
+}
+
+func topicIds(topics []*emissionstypes.Topic) []emissionstypes.TopicId {
+	ids := []emissionstypes.TopicId{}
+	for _, topic := range topics {
+		ids = append(ids, topic.Id)
+	}
+	return ids
+}
+
+func TestActiveTopicTrackerQueriesOnlyNewTopicsAndPassedChurningBlocks(t *testing.T) {
+	client := &topicsQueryClient{
+		nextTopicId:        3,
+		nextChurningBlocks: map[emissionstypes.TopicId]BlockHeight{1: 110, 2: 90},
+		topicQueries:       make(map[emissionstypes.TopicId]int),
+		churningQueries:    make(map[emissionstypes.TopicId]int),
+	}
+	node := &NodeConfig{}
+	node.Chain.EmissionsQueryClient = client
+	tracker := NewActiveTopicTracker(node)
+	height := BlockHeight(100)
+	tracker.latestBlockHeight = func(ctx context.Context) (BlockHeight, error) { return height, nil }
+
+	topics, err := tracker.ActiveTopics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []emissionstypes.TopicId{1}, topicIds(topics), "topic 2 churned last before the current block")
+
+	// A new topic is created and topic 2 is activated again
+	client.nextTopicId = 4
+	client.nextChurningBlocks[2] = 120
+	client.nextChurningBlocks[3] = 105
+	height = 105
+	topics, err = tracker.ActiveTopics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []emissionstypes.TopicId{1, 2, 3}, topicIds(topics))
+	assert.Equal(t, map[emissionstypes.TopicId]int{1: 1, 2: 1, 3: 1}, client.topicQueries, "topics are fetched once")
+	assert.Equal(t, map[emissionstypes.TopicId]int{1: 1, 2: 2, 3: 1}, client.churningQueries, "topic 1 has not churned yet")
+
+	// Once its churning block has passed, topic 1 is queried again and found inactive
+	client.nextChurningBlocks[1] = 0
+	height = 111
+	topics, err = tracker.ActiveTopics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []emissionstypes.TopicId{2}, topicIds(topics))
+	assert.Equal(t, map[emissionstypes.TopicId]int{1: 2, 2: 2, 3: 2}, client.churningQueries)
+}
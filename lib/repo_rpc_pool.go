@@ -0,0 +1,269 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	grpc1 "github.com/cosmos/gogoproto/grpc"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Weight of the latest call in the error rate of an endpoint
+const RPC_ERROR_RATE_WEIGHT = 0.2
+
+// An RPC endpoint of the chain, with the client bound to it and its health
+type RpcEndpoint struct {
+	Url    string
+	Client *cosmosclient.Client
+
+	conn         grpc1.ClientConn
+	latestHeight func(ctx context.Context) (int64, error)
+	broadcastTx  func(txBytes []byte) (*sdktypes.TxResponse, error)
+
+	// Health, guarded by the lock of the pool
+	height    int64
+	latency   time.Duration
+	errorRate float64 // moving average of failed calls and probes, from 0 to 1
+	probeErr  error
+}
+
+func NewRpcEndpoint(nodeRpc string, client *cosmosclient.Client) *RpcEndpoint {
+	return &RpcEndpoint{
+		Url:          nodeRpc,
+		Client:       client,
+		conn:         client.Context(),
+		latestHeight: client.LatestBlockHeight,
+		broadcastTx:  client.Context().BroadcastTx,
+	}
+}
+
+// Scheme and host of the endpoint, leaving out paths and queries that may carry api keys
+func (e *RpcEndpoint) Name() string {
+	endpoint, err := url.Parse(e.Url)
+	if err != nil || endpoint.Host == "" {
+		return "invalid"
+	}
+	return endpoint.Scheme + "://" + endpoint.Host
+}
+
+// RPC endpoints of the chain, ranked by health. Queries and broadcasts go to the healthiest endpoint,
+// failing over to the next ones on connection errors. Serves as the connection of the chain query clients.
+type RpcPool struct {
+	health RpcHealthConfig
+
+	mu        sync.RWMutex
+	endpoints []*RpcEndpoint // ranked, healthiest first
+	active    *RpcEndpoint
+}
+
+func NewRpcPool(endpoints []*RpcEndpoint, health RpcHealthConfig) *RpcPool {
+	pool := &RpcPool{health: health, endpoints: endpoints}
+	pool.rank()
+	return pool
+}
+
+// Healthiest endpoint
+func (p *RpcPool) Active() *RpcEndpoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.active
+}
+
+// Endpoints, healthiest first
+func (p *RpcPool) Ranked() []*RpcEndpoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*RpcEndpoint{}, p.endpoints...)
+}
+
+// Invoke the query on the healthiest endpoint, failing over to the next ones on connection errors
+func (p *RpcPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	var err error
+	for _, endpoint := range p.Ranked() {
+		err = endpoint.conn.Invoke(ctx, method, args, reply, opts...)
+		if ctx.Err() != nil || !isConnectionError(err) {
+			p.Report(endpoint, nil)
+			return err
+		}
+		p.Report(endpoint, err)
+		log.Warn().Err(err).Str("endpoint", endpoint.Name()).Str("method", method).Msg("Query failed on rpc endpoint, failing over")
+	}
+	return err
+}
+
+// Streams are not supported by the chain query clients, as with the client context
+func (p *RpcPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.Active().conn.NewStream(ctx, desc, method, opts...)
+}
+
+// Broadcast the signed tx to the healthiest endpoint, failing over to the next ones on connection errors
+func (p *RpcPool) BroadcastTx(txBytes []byte) (*sdktypes.TxResponse, error) {
+	var err error
+	for _, endpoint := range p.Ranked() {
+		var res *sdktypes.TxResponse
+		res, err = endpoint.broadcastTx(txBytes)
+		if err == nil {
+			p.Report(endpoint, nil)
+			return res, nil
+		}
+		p.Report(endpoint, err)
+		log.Warn().Err(err).Str("endpoint", endpoint.Name()).Msg("Broadcast failed on rpc endpoint, failing over")
+	}
+	return nil, err
+}
+
+// Height of the latest block on the healthiest endpoint, failing over to the next ones on errors
+func (p *RpcPool) LatestBlockHeight(ctx context.Context) (int64, error) {
+	var err error
+	for _, endpoint := range p.Ranked() {
+		var height int64
+		height, err = endpoint.latestHeight(ctx)
+		if err == nil || ctx.Err() != nil {
+			return height, err
+		}
+		p.Report(endpoint, err)
+		log.Warn().Err(err).Str("endpoint", endpoint.Name()).Msg("Latest block query failed on rpc endpoint, failing over")
+	}
+	return 0, err
+}
+
+// True unless the error was returned by the chain, as opposed to on the way to it.
+// Unknown is not a connection error: the chain returns application errors, e.g. not found, with that code.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		return true
+	}
+	return false
+}
+
+// Record the outcome of a call to the endpoint, re-ranking the endpoints on failure
+func (p *RpcPool) Report(endpoint *RpcEndpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	endpoint.errorRate = (1-RPC_ERROR_RATE_WEIGHT)*endpoint.errorRate + RPC_ERROR_RATE_WEIGHT*failed
+	if err != nil {
+		p.rankLocked()
+	}
+}
+
+// Probe the health of all endpoints every ProbeSeconds until ctx is done
+func (p *RpcPool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.health.ProbeInterval())
+	defer ticker.Stop()
+	for {
+		p.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe the latest height and latency of all endpoints concurrently, then re-rank them
+func (p *RpcPool) probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.health.ProbeInterval())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, endpoint := range p.Ranked() {
+		wg.Add(1)
+		go func(endpoint *RpcEndpoint) {
+			defer wg.Done()
+			start := time.Now()
+			height, err := endpoint.latestHeight(ctx)
+			latency := time.Since(start)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			endpoint.probeErr = err
+			failed := 1.0
+			if err == nil {
+				endpoint.height = height
+				endpoint.latency = latency
+				failed = 0
+			}
+			endpoint.errorRate = (1-RPC_ERROR_RATE_WEIGHT)*endpoint.errorRate + RPC_ERROR_RATE_WEIGHT*failed
+		}(endpoint)
+	}
+	wg.Wait()
+	p.rank()
+}
+
+func (p *RpcPool) rank() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rankLocked()
+}
+
+// Rank the endpoints: healthy ones first, then by error rate weighted latency.
+// An endpoint is unhealthy if its last probe failed or it lags the highest endpoint by more than MaxLagBlocks.
+func (p *RpcPool) rankLocked() {
+	maxHeight := int64(0)
+	for _, endpoint := range p.endpoints {
+		if endpoint.height > maxHeight {
+			maxHeight = endpoint.height
+		}
+	}
+	healthy := func(endpoint *RpcEndpoint) bool {
+		return endpoint.probeErr == nil && maxHeight-endpoint.height <= p.health.MaxLag()
+	}
+	score := func(endpoint *RpcEndpoint) float64 {
+		return float64(endpoint.latency.Milliseconds()+1) * (1 + 10*endpoint.errorRate)
+	}
+	sort.SliceStable(p.endpoints, func(i, j int) bool {
+		if healthy(p.endpoints[i]) != healthy(p.endpoints[j]) {
+			return healthy(p.endpoints[i])
+		}
+		return score(p.endpoints[i]) < score(p.endpoints[j])
+	})
+
+	if len(p.endpoints) == 0 || p.active == p.endpoints[0] {
+		return
+	}
+	if p.active != nil {
+		log.Info().Str("from", p.active.Name()).Str("to", p.endpoints[0].Name()).Msg("Switching active rpc endpoint")
+		rpcEndpointActiveGauge.WithLabelValues(p.active.Name()).Set(0)
+	}
+	p.active = p.endpoints[0]
+	rpcEndpointActiveGauge.WithLabelValues(p.active.Name()).Set(1)
+}
+
+// Create a client for each rpc endpoint. Endpoints that cannot be reached at startup are left out,
+// so at least one must be reachable.
+func newRpcEndpoints(config *UserConfig) ([]*RpcEndpoint, error) {
+	endpoints := []*RpcEndpoint{}
+	for _, nodeRpc := range config.Wallet.RpcEndpoints() {
+		client, err := getAlloraClient(config, nodeRpc)
+		if err != nil {
+			log.Warn().Err(err).Str("endpoint", (&RpcEndpoint{Url: nodeRpc}).Name()).Msg("Could not connect to rpc endpoint, leaving it out")
+			continue
+		}
+		endpoints = append(endpoints, NewRpcEndpoint(nodeRpc, client))
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("could not connect to any rpc endpoint")
+	}
+	return endpoints, nil
+}
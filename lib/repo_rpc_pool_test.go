@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeRpcConn struct {
+	err   error
+	calls int
+}
+
+func (c *fakeRpcConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	c.calls++
+	return c.err
+}
+
+func (c *fakeRpcConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("streams not supported")
+}
+
+func newFakeRpcEndpoint(url string, height int64, conn *fakeRpcConn) *RpcEndpoint {
+	return &RpcEndpoint{
+		Url:  url,
+		conn: conn,
+		latestHeight: func(ctx context.Context) (int64, error) {
+			if height == 0 {
+				return 0, errors.New("connection refused")
+			}
+			return height, nil
+		},
+		broadcastTx: func(txBytes []byte) (*sdktypes.TxResponse, error) {
+			if conn.err != nil {
+				return nil, conn.err
+			}
+			return &sdktypes.TxResponse{TxHash: url}, nil
+		},
+	}
+}
+
+func rankedUrls(pool *RpcPool) []string {
+	urls := []string{}
+	for _, endpoint := range pool.Ranked() {
+		urls = append(urls, endpoint.Url)
+	}
+	return urls
+}
+
+func TestRpcPoolRanksLaggingAndUnreachableEndpointsLast(t *testing.T) {
+	pool := NewRpcPool([]*RpcEndpoint{
+		newFakeRpcEndpoint("http://down:26657", 0, &fakeRpcConn{}),
+		newFakeRpcEndpoint("http://lagging:26657", 95, &fakeRpcConn{}),
+		newFakeRpcEndpoint("http://synced:26657", 100, &fakeRpcConn{}),
+	}, RpcHealthConfig{MaxLagBlocks: 3})
+	assert.Equal(t, "http://down:26657", pool.Active().Url, "configured order until probed")
+
+	pool.probe(context.Background())
+	ranked := rankedUrls(pool)
+	assert.Equal(t, "http://synced:26657", ranked[0])
+	assert.Equal(t, "http://synced:26657", pool.Active().Url)
+	assert.ElementsMatch(t, []string{"http://lagging:26657", "http://down:26657"}, ranked[1:])
+}
+
+func TestRpcPoolFailsOverOnConnectionErrors(t *testing.T) {
+	primary := &fakeRpcConn{err: status.Error(codes.Unavailable, "connection refused")}
+	secondary := &fakeRpcConn{}
+	pool := NewRpcPool([]*RpcEndpoint{
+		newFakeRpcEndpoint("http://primary:26657", 100, primary),
+		newFakeRpcEndpoint("http://secondary:26657", 100, secondary),
+	}, RpcHealthConfig{})
+
+	require.NoError(t, pool.Invoke(context.Background(), "/emissions.v4.QueryService/GetTopic", nil, nil))
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+	assert.Equal(t, "http://secondary:26657", pool.Active().Url, "failing endpoint ranked down")
+
+	res, err := pool.BroadcastTx([]byte("tx"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://secondary:26657", res.TxHash)
+}
+
+func TestRpcPoolReturnsChainErrorsWithoutFailingOver(t *testing.T) {
+	primary := &fakeRpcConn{err: status.Error(codes.NotFound, "topic not found")}
+	secondary := &fakeRpcConn{}
+	pool := NewRpcPool([]*RpcEndpoint{
+		newFakeRpcEndpoint("http://primary:26657", 100, primary),
+		newFakeRpcEndpoint("http://secondary:26657", 100, secondary),
+	}, RpcHealthConfig{})
+
+	err := pool.Invoke(context.Background(), "/emissions.v4.QueryService/GetTopic", nil, nil)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, 0, secondary.calls)
+	assert.Equal(t, "http://primary:26657", pool.Active().Url)
+}
+
+func TestRpcPoolReturnsUnknownApplicationErrorsWithoutFailingOver(t *testing.T) {
+	primary := &fakeRpcConn{err: status.Error(codes.Unknown, "inferer not found: unknown request")}
+	secondary := &fakeRpcConn{}
+	pool := NewRpcPool([]*RpcEndpoint{
+		newFakeRpcEndpoint("http://primary:26657", 100, primary),
+		newFakeRpcEndpoint("http://secondary:26657", 100, secondary),
+	}, RpcHealthConfig{})
+
+	for i := 0; i < 3; i++ {
+		err := pool.Invoke(context.Background(), "/emissions.v4.QueryService/GetInfererNetworkRegret", nil, nil)
+		assert.Equal(t, codes.Unknown, status.Code(err))
+	}
+	assert.Equal(t, 3, primary.calls)
+	assert.Equal(t, 0, secondary.calls)
+	assert.Equal(t, "http://primary:26657", pool.Active().Url)
+}
+
+func TestRpcHealthConfigDefaults(t *testing.T) {
+	assert.Equal(t, DEFAULT_RPC_PROBE_SECONDS*time.Second, RpcHealthConfig{}.ProbeInterval())
+	assert.Equal(t, int64(DEFAULT_RPC_MAX_LAG_BLOCKS), RpcHealthConfig{}.MaxLag())
+	assert.Equal(t, []string{"http://a", "http://b"}, WalletConfig{NodeRpc: "http://a", NodeRpcs: []string{"http://b", "http://a", ""}}.RpcEndpoints())
+}
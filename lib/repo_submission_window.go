@@ -0,0 +1,37 @@
+package lib
+
+import (
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+)
+
+// Blocks in which the chain accepts payloads for a nonce
+type SubmissionWindow struct {
+	Opens  BlockHeight // first block a payload may be included in
+	Closes BlockHeight // last block a payload may be included in
+}
+
+// Worker payloads for a nonce are accepted for WorkerSubmissionWindow blocks from the nonce.
+// Closed from the start if the topic sets no window, as the chain then accepts none.
+func WorkerSubmissionWindow(topic *emissionstypes.Topic, nonce BlockHeight) SubmissionWindow {
+	return SubmissionWindow{Opens: nonce, Closes: nonce + topic.WorkerSubmissionWindow - 1}
+}
+
+// Reputer payloads for a nonce are accepted once its ground truth is due, GroundTruthLag blocks after the nonce,
+// for another GroundTruthLag blocks
+func ReputerSubmissionWindow(topic *emissionstypes.Topic, nonce BlockHeight) SubmissionWindow {
+	return SubmissionWindow{Opens: nonce + topic.GroundTruthLag, Closes: nonce + 2*topic.GroundTruthLag}
+}
+
+// True once the window has opened at the current height
+func (w SubmissionWindow) HasOpened(currentHeight BlockHeight) bool {
+	return currentHeight >= w.Opens
+}
+
+// Time by which a payload must be broadcast to make it into the last block of the window, i.e. when the block
+// before it is expected, counting SECONDS_PER_BLOCK per block from the current height at now.
+// Not after now if the window already closed.
+func (w SubmissionWindow) Deadline(currentHeight BlockHeight, now time.Time) time.Time {
+	return now.Add(time.Duration(w.Closes-currentHeight-1) * SECONDS_PER_BLOCK * time.Second)
+}
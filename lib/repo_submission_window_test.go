@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmissionWindows(t *testing.T) {
+	topic := &emissionstypes.Topic{EpochLength: 60, WorkerSubmissionWindow: 10, GroundTruthLag: 60}
+	assert.Equal(t, SubmissionWindow{Opens: 1000, Closes: 1009}, WorkerSubmissionWindow(topic, 1000))
+	assert.Equal(t, SubmissionWindow{Opens: 1060, Closes: 1120}, ReputerSubmissionWindow(topic, 1000))
+
+	topic.WorkerSubmissionWindow = 0
+	window := WorkerSubmissionWindow(topic, 1000)
+	assert.Equal(t, SubmissionWindow{Opens: 1000, Closes: 999}, window, "empty without a window")
+	assert.True(t, window.Deadline(1000, time.Now()).Before(time.Now()), "closed without a window")
+}
+
+func TestSubmissionWindowHasOpened(t *testing.T) {
+	window := ReputerSubmissionWindow(&emissionstypes.Topic{GroundTruthLag: 60}, 1000)
+	assert.False(t, window.HasOpened(1001), "worker nonce just closed")
+	assert.False(t, window.HasOpened(1059))
+	assert.True(t, window.HasOpened(1060))
+	assert.True(t, window.HasOpened(1200))
+}
+
+func TestSubmissionWindowDeadline(t *testing.T) {
+	now := time.Now()
+	window := SubmissionWindow{Opens: 1000, Closes: 1009}
+	assert.Equal(t, now.Add(4*SECONDS_PER_BLOCK*time.Second), window.Deadline(1004, now), "when the block before the last one is expected")
+	assert.Equal(t, now, window.Deadline(1008, now), "the next block is the last one")
+	assert.True(t, window.Deadline(1010, now).Before(now), "closed")
+}
@@ -0,0 +1,139 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const NEW_BLOCK_SUBSCRIPTION_QUERY = "tm.event='NewBlock'"
+
+// Emitted by the emissions module when a topic's worker nonce closes, which opens the reputer nonce for it
+const EVENT_WORKER_LAST_COMMIT_SET = "emissions.v4.EventWorkerLastCommitSet"
+
+// A new block, as received over the CometBFT websocket
+type NewBlockEvent struct {
+	Height BlockHeight
+	// Attribute values of the events of the block, keyed by "<event type>.<attribute key>" as indexed by CometBFT
+	Events map[string][]string
+}
+
+// Ids of the topics the events of the given type in the block refer to
+func (e NewBlockEvent) TopicIds(eventType string) []emissionstypes.TopicId {
+	topicIds := []emissionstypes.TopicId{}
+	for _, value := range e.Events[eventType+".topic_id"] {
+		// Typed event attributes are JSON encoded, so uint64 values come quoted
+		topicId, err := strconv.ParseUint(strings.Trim(value, `"`), 10, 64)
+		if err != nil {
+			log.Warn().Err(err).Str("eventType", eventType).Str("value", value).Msg("Could not parse topic id of event")
+			continue
+		}
+		topicIds = append(topicIds, topicId)
+	}
+	return topicIds
+}
+
+// Websocket endpoint of the node, derived from its rpc address unless configured explicitly
+func (c SubscriptionConfig) WebsocketEndpoint(nodeRpc string) (string, error) {
+	if c.WebsocketUrl != "" {
+		return c.WebsocketUrl, nil
+	}
+	endpoint, err := url.Parse(nodeRpc)
+	if err != nil {
+		return "", fmt.Errorf("invalid node rpc %s: %w", nodeRpc, err)
+	}
+	switch endpoint.Scheme {
+	case "https":
+		endpoint.Scheme = "wss"
+	case "http", "tcp":
+		endpoint.Scheme = "ws"
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/websocket"
+	return endpoint.String(), nil
+}
+
+type jsonRpcRequest struct {
+	JsonRpc string            `json:"jsonrpc"`
+	Method  string            `json:"method"`
+	Id      int               `json:"id"`
+	Params  map[string]string `json:"params"`
+}
+
+type jsonRpcResponse struct {
+	Error  *struct{ Message, Data string } `json:"error"`
+	Result struct {
+		Data struct {
+			Value struct {
+				Block struct {
+					Header struct {
+						Height string `json:"height"`
+					} `json:"header"`
+				} `json:"block"`
+			} `json:"value"`
+		} `json:"data"`
+		Events map[string][]string `json:"events"`
+	} `json:"result"`
+}
+
+// Subscribe to new blocks over the CometBFT websocket at websocketUrl.
+// The returned channel is closed when the subscription drops or ctx is done.
+func SubscribeNewBlocks(ctx context.Context, websocketUrl string) (<-chan NewBlockEvent, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, websocketUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket %s: %w", websocketUrl, err)
+	}
+
+	subscribe := jsonRpcRequest{
+		JsonRpc: "2.0",
+		Method:  "subscribe",
+		Id:      1,
+		Params:  map[string]string{"query": NEW_BLOCK_SUBSCRIPTION_QUERY},
+	}
+	if err := conn.WriteJSON(subscribe); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to new blocks: %w", err)
+	}
+
+	events := make(chan NewBlockEvent)
+	// Unblock the reader below on cancellation
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer close(events)
+		defer stop()
+		defer conn.Close()
+		for {
+			var res jsonRpcResponse
+			if err := conn.ReadJSON(&res); err != nil {
+				if ctx.Err() == nil {
+					log.Warn().Err(err).Str("url", websocketUrl).Msg("New block subscription dropped")
+				}
+				return
+			}
+			if res.Error != nil {
+				log.Error().Str("error", res.Error.Message).Str("data", res.Error.Data).Msg("New block subscription failed")
+				return
+			}
+			// The acknowledgement of the subscription carries no block
+			if res.Result.Data.Value.Block.Header.Height == "" {
+				continue
+			}
+			height, err := strconv.ParseInt(res.Result.Data.Value.Block.Header.Height, 10, 64)
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not parse height of new block")
+				continue
+			}
+			select {
+			case events <- NewBlockEvent{Height: height, Events: res.Result.Events}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
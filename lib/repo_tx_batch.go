@@ -0,0 +1,217 @@
+package lib
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Index of the failed message, as reported by the chain for multi-message transactions
+var messageIndexRegex = regexp.MustCompile(`message index: (\d+)`)
+
+// A batch tx needs more gas than the configured bound
+type batchGasError struct {
+	gas    uint64
+	maxGas uint64
+}
+
+func (e *batchGasError) Error() string {
+	return fmt.Sprintf("batch needs %d gas, more than the maximum of %d", e.gas, e.maxGas)
+}
+
+// True if the message may be batched with others. Only payloads are batched
+func (b *TxBroadcaster) isBatchable(req *txRequest) bool {
+	return b.batching.Enabled && !req.unbatched && isPayload(req.msg)
+}
+
+// Collect the batchable messages queued within the batching window, starting with req
+func (b *TxBroadcaster) collectBatch(ctx context.Context, req *txRequest) []*txRequest {
+	batch := []*txRequest{req}
+	maxMsgs := b.batching.MaxBatchMsgs()
+	timer := time.NewTimer(b.batching.Window())
+	defer timer.Stop()
+	for {
+		batch = append(batch, b.popBatchable(maxMsgs-len(batch))...)
+		if len(batch) >= maxMsgs {
+			return batch
+		}
+		select {
+		case <-ctx.Done():
+			return batch
+		case <-timer.C:
+			return batch
+		case <-b.wake:
+		}
+	}
+}
+
+// Pop up to n batchable messages in queue order, leaving the others queued
+func (b *TxBroadcaster) popBatchable(n int) []*txRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batchable := []*txRequest{}
+	others := []*txRequest{}
+	for b.queue.Len() > 0 && len(batchable) < n {
+		req := heap.Pop(&b.queue).(*txRequest)
+		if b.isBatchable(req) {
+			batchable = append(batchable, req)
+		} else {
+			others = append(others, req)
+		}
+	}
+	for _, req := range others {
+		heap.Push(&b.queue, req)
+	}
+	return batchable
+}
+
+// Queue messages again, in their original order of arrival
+func (b *TxBroadcaster) requeue(reqs []*txRequest, unbatched bool) {
+	if len(reqs) == 0 {
+		return
+	}
+	b.mu.Lock()
+	for _, req := range reqs {
+		if b.stopped {
+			req.outcome <- txOutcome{err: fmt.Errorf("tx broadcaster stopped: %s", req.infoMsg)}
+			continue
+		}
+		req.unbatched = req.unbatched || unbatched
+		heap.Push(&b.queue, req)
+	}
+	b.mu.Unlock()
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Broadcast the messages in one tx, trimmed to the gas bound. Its inclusion is awaited in the background,
+// reporting the result of each message back to its caller. If the batch fails, its messages are sent individually.
+func (b *TxBroadcaster) sendBatch(ctx context.Context, batch []*txRequest) {
+	// Drop the messages whose caller gave up while queued
+	live := []*txRequest{}
+	for _, req := range batch {
+		if err := req.ctx.Err(); err != nil {
+			req.outcome <- txOutcome{err: errorsmod.Wrapf(err, "tx aborted while queued: %s", req.infoMsg)}
+			continue
+		}
+		live = append(live, req)
+	}
+	if len(live) <= 1 {
+		for _, req := range live {
+			b.sendSingle(req)
+		}
+		return
+	}
+
+	batchCtx, cancel := batchContext(ctx, live)
+
+	result, err := b.broadcastBatch(batchCtx, batchMsgs(live))
+	var gasErr *batchGasError
+	if errors.As(err, &gasErr) {
+		// Keep as many messages as fit the bound at their average gas, leaving the others for the next batch
+		fit := int(uint64(len(live)) * gasErr.maxGas / gasErr.gas)
+		if fit < 1 {
+			fit = 1
+		}
+		log.Info().Int("msgs", len(live)).Int("fit", fit).Err(err).Msg("Trimming batch to the gas bound")
+		b.requeue(live[fit:], false)
+		live = live[:fit]
+		if len(live) == 1 {
+			cancel()
+			b.sendSingle(live[0])
+			return
+		}
+		result, err = b.broadcastBatch(batchCtx, batchMsgs(live))
+	}
+	if err != nil {
+		cancel()
+		log.Warn().Err(err).Int("msgs", len(live)).Msg("Batch tx failed, falling back to individual txs")
+		b.requeue(live, true)
+		return
+	}
+	log.Info().Str("txHash", result.TxHash).Int("msgs", len(live)).Msg("Batch tx broadcast")
+
+	go func() {
+		defer cancel()
+		b.confirmBatch(batchCtx, live, result)
+	}()
+}
+
+// Context due by the earliest deadline of the messages, if any
+func batchContext(ctx context.Context, batch []*txRequest) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, req := range batch {
+		if !req.deadline.IsZero() && (deadline.IsZero() || req.deadline.Before(deadline)) {
+			deadline = req.deadline
+		}
+	}
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+func batchMsgs(batch []*txRequest) []sdktypes.Msg {
+	msgs := make([]sdktypes.Msg, 0, len(batch))
+	for _, req := range batch {
+		msgs = append(msgs, req.msg)
+	}
+	return msgs
+}
+
+// Broadcast the messages in one tx, once, bounded by the configured gas
+func (b *TxBroadcaster) sendBatchOnce(ctx context.Context, msgs []sdktypes.Msg) (*TxResult, error) {
+	result, err := b.broadcastOnce(ctx, 0, b.batching.MaxBatchGas(), msgs...)
+	if err != nil {
+		b.reconcileSequence(err)
+	}
+	return result, err
+}
+
+// Wait for the batch tx to be included and report the result of each message back to its caller.
+// A failed batch is attributed to the message the chain names, if any, and the others are sent individually.
+func (b *TxBroadcaster) confirmBatch(ctx context.Context, batch []*txRequest, result *TxResult) {
+	inclusion, err := b.awaitDelivery(ctx, result.TxHash)
+	if err == nil || errors.Is(err, errTxNotIncluded) {
+		// Each caller queues its payload again if the batch was not included
+		for i, req := range batch {
+			req.outcome <- txOutcome{
+				result:   &TxResult{TxHash: result.TxHash, Fees: result.Fees, MsgIndex: i, BatchSize: len(batch), Inclusion: inclusion},
+				err:      err,
+				included: err == nil,
+			}
+		}
+		return
+	}
+
+	failed := -1
+	if matches := messageIndexRegex.FindStringSubmatch(err.Error()); len(matches) == 2 {
+		failed, _ = strconv.Atoi(matches[1])
+	}
+	log.Warn().Err(err).Str("txHash", result.TxHash).Int("failedMsgIndex", failed).Msg("Batch tx failed, sending its messages individually")
+	retry := []*txRequest{}
+	for i, req := range batch {
+		if i != failed {
+			retry = append(retry, req)
+			continue
+		}
+		if strings.Contains(err.Error(), ERROR_MESSAGE_DATA_ALREADY_SUBMITTED) || strings.Contains(err.Error(), ERROR_MESSAGE_CANNOT_UPDATE_EMA) {
+			log.Warn().Err(err).Str("msg", req.infoMsg).Msg("Already submitted data for this epoch.")
+			req.outcome <- txOutcome{included: true}
+			continue
+		}
+		req.outcome <- txOutcome{err: errorsmod.Wrapf(err, "batch tx %s failed", result.TxHash)}
+	}
+	b.requeue(retry, true)
+}
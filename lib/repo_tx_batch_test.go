@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sendResult struct {
+	result *TxResult
+	err    error
+}
+
+// Queue the messages one at a time, so their order of arrival is fixed, and collect the results by message
+func queueInOrder(t *testing.T, b *TxBroadcaster, msgs map[string]sdktypes.Msg, order []string) map[string]chan sendResult {
+	results := map[string]chan sendResult{}
+	for i, infoMsg := range order {
+		results[infoMsg] = make(chan sendResult, 1)
+		go func(infoMsg string) {
+			result, err := b.Send(context.Background(), msgs[infoMsg], infoMsg, TxPriorityHigh)
+			results[infoMsg] <- sendResult{result, err}
+		}(infoMsg)
+		require.Eventually(t, func() bool { return b.queued() == i+1 }, time.Second, time.Millisecond)
+	}
+	return results
+}
+
+func receive(t *testing.T, results chan sendResult) sendResult {
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(time.Second):
+		t.Fatal("no result")
+		return sendResult{}
+	}
+}
+
+func TestTxBroadcasterBatchFallsBackToIndividualTxs(t *testing.T) {
+	var mu sync.Mutex
+	singles := []string{}
+	b := newTestTxBroadcaster(func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		singles = append(singles, infoMsg)
+		return &TxResult{TxHash: infoMsg, BatchSize: 1}, nil
+	})
+	b.batching = BatchingConfig{Enabled: true, WindowMilliseconds: 10, MaxMsgs: 3}
+	batches := [][]sdktypes.Msg{}
+	b.broadcastBatch = func(ctx context.Context, msgs []sdktypes.Msg) (*TxResult, error) {
+		batches = append(batches, msgs)
+		return &TxResult{TxHash: "batch", BatchSize: len(msgs)}, nil
+	}
+	// The second payload of the batch was already submitted, which fails the whole batch
+	b.awaitDelivery = func(ctx context.Context, txHash string) (*TxInclusion, error) {
+		return &TxInclusion{Height: 10, Code: 1}, errors.New("error code: '1' msg: 'failed to execute message; message index: 1: already submitted'")
+	}
+
+	msgs := map[string]sdktypes.Msg{
+		"worker 1":   &emissionstypes.InsertWorkerPayloadRequest{Sender: "1"},
+		"reputer 2":  &emissionstypes.InsertReputerPayloadRequest{Sender: "2"},
+		"register 3": &emissionstypes.RegisterRequest{Sender: "3"},
+		"worker 4":   &emissionstypes.InsertWorkerPayloadRequest{Sender: "4"},
+		"worker 5":   &emissionstypes.InsertWorkerPayloadRequest{Sender: "5"},
+	}
+	results := queueInOrder(t, b, msgs, []string{"worker 1", "reputer 2", "register 3", "worker 4", "worker 5"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	for _, infoMsg := range []string{"worker 1", "worker 4", "register 3", "worker 5"} {
+		res := receive(t, results[infoMsg])
+		require.NoError(t, res.err, infoMsg)
+		assert.Equal(t, infoMsg, res.result.TxHash, "sent individually after the failed batch")
+	}
+	res := receive(t, results["reputer 2"])
+	assert.NoError(t, res.err)
+	assert.Nil(t, res.result, "already submitted payload is not sent again")
+
+	require.Len(t, batches, 1)
+	assert.Equal(t, []sdktypes.Msg{msgs["worker 1"], msgs["reputer 2"], msgs["worker 4"]}, batches[0], "payloads batched around other messages")
+	// The batch is confirmed in the background, so the writer may have moved on before its fallback is queued
+	assert.ElementsMatch(t, []string{"worker 1", "worker 4", "worker 5", "register 3"}, singles)
+}
+
+func TestTxBroadcasterBatchTrimmedToGasBound(t *testing.T) {
+	b := newTestTxBroadcaster(func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error) {
+		return &TxResult{TxHash: infoMsg, BatchSize: 1}, nil
+	})
+	b.batching = BatchingConfig{Enabled: true, WindowMilliseconds: 10, MaxMsgs: 10}
+	b.broadcastBatch = func(ctx context.Context, msgs []sdktypes.Msg) (*TxResult, error) {
+		if len(msgs) > 2 {
+			return nil, &batchGasError{gas: uint64(len(msgs)) * 100, maxGas: 250}
+		}
+		return &TxResult{TxHash: "batch", BatchSize: len(msgs)}, nil
+	}
+	b.awaitDelivery = func(ctx context.Context, txHash string) (*TxInclusion, error) {
+		return &TxInclusion{Height: 10}, nil
+	}
+
+	msgs := map[string]sdktypes.Msg{
+		"worker 1": &emissionstypes.InsertWorkerPayloadRequest{Sender: "1"},
+		"worker 2": &emissionstypes.InsertWorkerPayloadRequest{Sender: "2"},
+		"worker 3": &emissionstypes.InsertWorkerPayloadRequest{Sender: "3"},
+	}
+	results := queueInOrder(t, b, msgs, []string{"worker 1", "worker 2", "worker 3"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	for i, infoMsg := range []string{"worker 1", "worker 2"} {
+		res := receive(t, results[infoMsg])
+		require.NoError(t, res.err)
+		assert.Equal(t, &TxResult{TxHash: "batch", MsgIndex: i, BatchSize: 2, Inclusion: &TxInclusion{Height: 10}}, res.result)
+	}
+	res := receive(t, results["worker 3"])
+	require.NoError(t, res.err)
+	assert.Equal(t, "worker 3", res.result.TxHash, "left over for the next batch, alone in it")
+}
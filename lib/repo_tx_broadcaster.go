@@ -0,0 +1,446 @@
+package lib
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"github.com/rs/zerolog/log"
+)
+
+// Priority of a queued transaction. Higher priorities are broadcast first
+type TxPriority int
+
+const (
+	TxPriorityNormal TxPriority = iota // registration and staking
+	TxPriorityHigh                     // payloads, which must land before their nonce closes
+)
+
+// Result of a queued transaction, as reported back to the caller
+type txOutcome struct {
+	result   *TxResult
+	err      error
+	included bool // the tx was already awaited for inclusion
+}
+
+// Transaction queued with the broadcaster
+type txRequest struct {
+	ctx       context.Context
+	msg       sdktypes.Msg
+	infoMsg   string
+	priority  TxPriority
+	deadline  time.Time // zero if the caller set no deadline
+	index     uint64    // order of arrival, breaking ties
+	unbatched bool      // send in a tx of its own, after a failed batch
+	requeues  int64     // times queued again after its tx was never included
+	outcome   chan txOutcome
+}
+
+// Pending transactions by priority, then earliest deadline, then order of arrival
+type txQueue []*txRequest
+
+func (q txQueue) Len() int { return len(q) }
+func (q txQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	if !q[i].deadline.Equal(q[j].deadline) {
+		if q[i].deadline.IsZero() || q[j].deadline.IsZero() {
+			return q[j].deadline.IsZero()
+		}
+		return q[i].deadline.Before(q[j].deadline)
+	}
+	return q[i].index < q[j].index
+}
+func (q txQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *txQueue) Push(x interface{}) { *q = append(*q, x.(*txRequest)) }
+func (q *txQueue) Pop() interface{} {
+	old := *q
+	req := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return req
+}
+
+// Signs and broadcasts the transactions of all actors from a single goroutine, which owns the account sequence.
+// Actors queue their messages with Send. Each broadcast tx is awaited for inclusion by its caller, not by the
+// broadcaster, so the next tx can go out right away with the next sequence.
+type TxBroadcaster struct {
+	node         *NodeConfig
+	batching     BatchingConfig
+	confirmation ConfirmationConfig
+	oracle       *GasPriceOracle
+	// Broadcast a message, retrying on errors. Only called from the Run goroutine
+	broadcast func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error)
+	// Broadcast messages in one tx, once. Only called from the Run goroutine
+	broadcastBatch func(ctx context.Context, msgs []sdktypes.Msg) (*TxResult, error)
+	// Wait for a broadcast tx to be included in a block
+	awaitInclusion func(ctx context.Context, result *TxResult) error
+	// Wait for a broadcast tx to be included in a block, returning the error it failed with if any
+	awaitDelivery func(ctx context.Context, txHash string) (*TxInclusion, error)
+
+	mu       sync.Mutex
+	queue    txQueue
+	arrivals uint64
+	stopped  bool
+	wake     chan struct{}
+
+	// Account sequence to sign the next tx with, owned by the Run goroutine
+	accountNumber uint64
+	sequence      uint64
+	sequenceKnown bool
+}
+
+func NewTxBroadcaster(node *NodeConfig) *TxBroadcaster {
+	b := &TxBroadcaster{
+		node:         node,
+		batching:     node.Batching,
+		confirmation: node.Confirmation,
+		oracle:       NewGasPriceOracle(node),
+		wake:         make(chan struct{}, 1),
+	}
+	b.broadcast = b.sendWithRetry
+	b.broadcastBatch = b.sendBatchOnce
+	b.awaitInclusion = b.waitForInclusion
+	b.awaitDelivery = b.confirmTx
+	return b
+}
+
+// Queue the message and wait until it is broadcast and included in a block, or ctx is done.
+// The deadline of ctx, if any, orders the message among others of the same priority.
+// A payload accepted into the mempool but not included in time is queued again, up to MaxRequeues times.
+func (b *TxBroadcaster) Send(ctx context.Context, msg sdktypes.Msg, infoMsg string, priority TxPriority) (*TxResult, error) {
+	req := &txRequest{
+		ctx:      ctx,
+		msg:      msg,
+		infoMsg:  infoMsg,
+		priority: priority,
+		outcome:  make(chan txOutcome, 1),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.deadline = deadline
+	}
+
+	for {
+		if err := b.enqueue(req); err != nil {
+			return nil, err
+		}
+
+		var outcome txOutcome
+		select {
+		case <-ctx.Done():
+			return nil, errorsmod.Wrapf(ctx.Err(), "tx aborted while queued: %s", infoMsg)
+		case outcome = <-req.outcome:
+		}
+		result, err := outcome.result, outcome.err
+		if err == nil && result != nil && !outcome.included {
+			err = b.awaitInclusion(ctx, result)
+		}
+		if !errors.Is(err, errTxNotIncluded) {
+			return result, err
+		}
+
+		if !isPayload(msg) {
+			// Sending registration or stake again could apply it twice, should the first tx still be included
+			log.Warn().Err(err).Str("msg", infoMsg).Msg("Tx not confirmed included in a block, not sending it again")
+			return result, nil
+		}
+		if ctx.Err() != nil || req.requeues >= b.confirmation.MaxRequeueCount() {
+			return result, errorsmod.Wrapf(err, "giving up on %s", infoMsg)
+		}
+		req.requeues++
+		log.Warn().Err(err).Str("msg", infoMsg).Int64("requeues", req.requeues).Msg("Payload not included in a block, queueing it again")
+	}
+}
+
+// Push the message to the queue and wake the broadcaster
+func (b *TxBroadcaster) enqueue(req *txRequest) error {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return fmt.Errorf("tx broadcaster stopped: %s", req.infoMsg)
+	}
+	req.index = b.arrivals
+	b.arrivals++
+	heap.Push(&b.queue, req)
+	b.mu.Unlock()
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// True for worker and reputer payloads
+func isPayload(msg sdktypes.Msg) bool {
+	switch msg.(type) {
+	case *emissionstypes.InsertWorkerPayloadRequest, *emissionstypes.InsertReputerPayloadRequest:
+		return true
+	}
+	return false
+}
+
+// Broadcast queued messages one at a time until ctx is done, then fail the ones still queued
+func (b *TxBroadcaster) Run(ctx context.Context) {
+	defer b.stop()
+	for ctx.Err() == nil {
+		req := b.next()
+		if req == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.wake:
+				continue
+			}
+		}
+
+		if b.isBatchable(req) {
+			b.sendBatch(ctx, b.collectBatch(ctx, req))
+		} else {
+			b.sendSingle(req)
+		}
+	}
+}
+
+// Broadcast the message in a tx of its own and report the result back to the caller
+func (b *TxBroadcaster) sendSingle(req *txRequest) {
+	// The caller gave up while the message was queued
+	if err := req.ctx.Err(); err != nil {
+		req.outcome <- txOutcome{err: errorsmod.Wrapf(err, "tx aborted while queued: %s", req.infoMsg)}
+		return
+	}
+	result, err := b.broadcast(req.ctx, req.msg, req.infoMsg)
+	req.outcome <- txOutcome{result: result, err: err}
+}
+
+// Pop the next message to broadcast, nil if there is none
+func (b *TxBroadcaster) next() *txRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.queue.Len() == 0 {
+		return nil
+	}
+	return heap.Pop(&b.queue).(*txRequest)
+}
+
+func (b *TxBroadcaster) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopped = true
+	for b.queue.Len() > 0 {
+		req := heap.Pop(&b.queue).(*txRequest)
+		req.outcome <- txOutcome{err: fmt.Errorf("tx broadcaster stopped: %s", req.infoMsg)}
+	}
+}
+
+// Client of the active rpc endpoint. The keyring is shared by the clients of all endpoints.
+func (b *TxBroadcaster) client() *cosmosclient.Client {
+	if b.node.Chain.Rpc == nil {
+		return b.node.Chain.Client
+	}
+	return b.node.Chain.Rpc.Active().Client
+}
+
+// Fetch the account number and sequence from the chain
+func (b *TxBroadcaster) fetchSequence() error {
+	address, err := b.node.Chain.Account.Record.GetAddress()
+	if err != nil {
+		return errorsmod.Wrapf(err, "failed to get account address")
+	}
+	clientCtx := b.client().Context()
+	accountNumber, sequence, err := clientCtx.AccountRetriever.GetAccountNumberSequence(clientCtx, address)
+	if err != nil {
+		return errorsmod.Wrapf(err, "failed to get account sequence")
+	}
+	log.Debug().Uint64("accountNumber", accountNumber).Uint64("sequence", sequence).Msg("Fetched account sequence from chain")
+	b.accountNumber = accountNumber
+	b.sequence = sequence
+	b.sequenceKnown = true
+	return nil
+}
+
+// Reconcile the local sequence with the chain after a failed broadcast.
+// Takes the expected sequence from a mismatch error, else fetches it from the chain before the next tx.
+func (b *TxBroadcaster) reconcileSequence(err error) {
+	if !strings.Contains(err.Error(), ERROR_MESSAGE_ACCOUNT_SEQUENCE_MISMATCH) {
+		return
+	}
+	expected, current, parseErr := parseSequenceFromAccountMismatchError(err.Error())
+	if parseErr != nil {
+		log.Warn().Err(parseErr).Msg("Failed to parse sequence from error, fetching it from chain")
+		b.sequenceKnown = false
+		return
+	}
+	log.Info().Uint64("expected", expected).Uint64("current", current).Msg("Resetting account sequence from current to expected")
+	b.sequence = expected
+}
+
+// Gas for the messages: the configured amount, else simulated
+func (b *TxBroadcaster) estimateGas(client *cosmosclient.Client, txf tx.Factory, msgs ...sdktypes.Msg) (uint64, error) {
+	if b.node.Wallet.Gas != "" && b.node.Wallet.Gas != "auto" {
+		return strconv.ParseUint(b.node.Wallet.Gas, 10, 64)
+	}
+	_, gas, err := tx.CalculateGas(client.Context(), txf, msgs...)
+	if err != nil {
+		return 0, err
+	}
+	// Simulated gas can vary from the gas the tx actually needs
+	return gas + EXCESS_CORRECTION_IN_GAS, nil
+}
+
+// Fees for the gas at the gas price of the fee strategy, increased with each retry and capped at MaxFees.
+// Empty if the gas price is 0, e.g. no gas prices are configured.
+func (b *TxBroadcaster) fees(ctx context.Context, gas uint64, retryCount int64) string {
+	gasPrice := b.oracle.GasPrice(ctx)
+	if gasPrice <= 0 {
+		return ""
+	}
+	// Excess fees correction factor translated to fees using the gas price
+	excessFactorFees := float64(EXCESS_CORRECTION_IN_GAS) * gasPrice
+	fees := uint64(math.Ceil(float64(gas+EXCESS_CORRECTION_IN_GAS) * gasPrice))
+	fees = fees + uint64(float64(retryCount+1)*excessFactorFees)
+	if fees > b.node.Wallet.MaxFees {
+		log.Warn().Uint64("gas", gas).Uint64("limit", b.node.Wallet.MaxFees).Msg("Gas limit exceeded, using maxFees instead")
+		fees = b.node.Wallet.MaxFees
+	}
+	return fmt.Sprintf("%d%s", fees, DEFAULT_BOND_DENOM)
+}
+
+// Sign the messages with the local sequence and broadcast them in one tx, without waiting for inclusion.
+// The sequence is only advanced once the tx is accepted into the mempool.
+// Returns a *batchGasError without broadcasting if maxGas is not 0 and the tx needs more gas than that.
+func (b *TxBroadcaster) broadcastOnce(ctx context.Context, retryCount int64, maxGas uint64, msgs ...sdktypes.Msg) (*TxResult, error) {
+	if !b.sequenceKnown {
+		if err := b.fetchSequence(); err != nil {
+			return nil, err
+		}
+	}
+
+	client := b.client()
+	txf := client.TxFactory.
+		WithAccountNumber(b.accountNumber).
+		WithSequence(b.sequence)
+	if b.node.Wallet.GasAdjustment > 0 {
+		txf = txf.WithGasAdjustment(b.node.Wallet.GasAdjustment)
+	}
+	gas, err := b.estimateGas(client, txf, msgs...)
+	if err != nil {
+		return nil, err
+	}
+	if maxGas != 0 && gas > maxGas {
+		return nil, &batchGasError{gas: gas, maxGas: maxGas}
+	}
+	fees := b.fees(ctx, gas, retryCount)
+	txf = txf.WithGas(gas).WithFees(fees)
+	log.Debug().Uint64("sequence", b.sequence).Uint64("gas", gas).Str("fees", fees).Msg("Signing tx")
+
+	txBuilder, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Sign(ctx, txf, b.node.Chain.Account.Name, txBuilder, true); err != nil {
+		return nil, errorsmod.Wrapf(err, "failed to sign tx")
+	}
+	txBytes, err := client.Context().TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, errorsmod.Wrapf(err, "failed to encode tx")
+	}
+
+	var res *sdktypes.TxResponse
+	if b.node.Chain.Rpc != nil {
+		res, err = b.node.Chain.Rpc.BroadcastTx(txBytes)
+	} else {
+		res, err = client.Context().BroadcastTx(txBytes)
+	}
+	if err != nil {
+		// The tx may or may not have reached the mempool
+		b.sequenceKnown = false
+		return nil, err
+	}
+	if res.Code != 0 {
+		// Same format as cosmosclient, which processError classifies by
+		return nil, fmt.Errorf("error code: '%d' msg: '%s'", res.Code, res.RawLog)
+	}
+	b.sequence++
+	return &TxResult{TxHash: res.TxHash, Fees: fees, BatchSize: len(msgs)}, nil
+}
+
+// Wait for the tx to be included in a block and check that it was executed successfully, recording its inclusion in result.
+// Data already submitted for the epoch counts as success.
+func (b *TxBroadcaster) waitForInclusion(ctx context.Context, result *TxResult) error {
+	inclusion, err := b.awaitDelivery(ctx, result.TxHash)
+	result.Inclusion = inclusion
+	if err == nil {
+		log.Debug().Str("txHash", result.TxHash).Int64("height", inclusion.Height).Int64("gasUsed", inclusion.GasUsed).Str("feePaid", inclusion.FeePaid).Msg("Tx included in a block")
+		return nil
+	}
+	if errors.Is(err, errTxNotIncluded) {
+		return err
+	}
+	if strings.Contains(err.Error(), ERROR_MESSAGE_DATA_ALREADY_SUBMITTED) || strings.Contains(err.Error(), ERROR_MESSAGE_CANNOT_UPDATE_EMA) {
+		log.Warn().Err(err).Str("txHash", result.TxHash).Msg("Already submitted data for this epoch.")
+		return nil
+	}
+	return errorsmod.Wrapf(err, "tx %s failed", result.TxHash)
+}
+
+// Poll for the tx until it is included in a block or the confirmation timeout elapses, whichever comes first with ctx.
+// Returns the inclusion with the error the tx failed with if any, and errTxNotIncluded if it was not included in time.
+func (b *TxBroadcaster) confirmTx(ctx context.Context, txHash string) (*TxInclusion, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, b.confirmation.Timeout())
+	defer cancel()
+
+	for {
+		res, err := b.client().WaitForTx(waitCtx, txHash)
+		if err == nil {
+			inclusion := newTxInclusion(res)
+			if inclusion.Code == 0 {
+				return inclusion, nil
+			}
+			// Same format as cosmosclient, which processError classifies by
+			return inclusion, fmt.Errorf("error code: '%d' msg: '%s'", inclusion.Code, inclusion.Log)
+		}
+		if waitCtx.Err() != nil {
+			return nil, errorsmod.Wrapf(errTxNotIncluded, "tx %s", txHash)
+		}
+		// The rpc endpoint failed, poll again once the pool may have failed over
+		log.Warn().Err(err).Str("txHash", txHash).Msg("Could not poll for tx, retrying")
+		if sleepWithContext(waitCtx, time.Second) != nil {
+			return nil, errorsmod.Wrapf(errTxNotIncluded, "tx %s", txHash)
+		}
+	}
+}
+
+func newTxInclusion(res *coretypes.ResultTx) *TxInclusion {
+	inclusion := &TxInclusion{
+		Height:    res.Height,
+		GasWanted: res.TxResult.GasWanted,
+		GasUsed:   res.TxResult.GasUsed,
+		Code:      res.TxResult.Code,
+		Codespace: res.TxResult.Codespace,
+		Log:       res.TxResult.Log,
+		Events:    res.TxResult.Events,
+	}
+	for _, event := range res.TxResult.Events {
+		if event.Type != sdktypes.EventTypeTx {
+			continue
+		}
+		for _, attribute := range event.Attributes {
+			if attribute.Key == sdktypes.AttributeKeyFee {
+				inclusion.FeePaid = attribute.Value
+			}
+		}
+	}
+	return inclusion
+}
@@ -0,0 +1,180 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTxBroadcaster(broadcast func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error)) *TxBroadcaster {
+	b := NewTxBroadcaster(&NodeConfig{})
+	b.broadcast = broadcast
+	b.awaitInclusion = func(ctx context.Context, result *TxResult) error { return nil }
+	return b
+}
+
+func (b *TxBroadcaster) queued() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queue.Len()
+}
+
+func TestTxBroadcasterOrdersByPriorityThenDeadline(t *testing.T) {
+	var mu sync.Mutex
+	broadcastOrder := []string{}
+	b := newTestTxBroadcaster(func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		broadcastOrder = append(broadcastOrder, infoMsg)
+		return &TxResult{TxHash: infoMsg}, nil
+	})
+
+	send := func(infoMsg string, priority TxPriority, deadline time.Duration) <-chan *TxResult {
+		results := make(chan *TxResult, 1)
+		go func() {
+			ctx := context.Background()
+			if deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, deadline)
+				defer cancel()
+			}
+			result, err := b.Send(ctx, &emissionstypes.RegisterRequest{}, infoMsg, priority)
+			assert.NoError(t, err)
+			results <- result
+		}()
+		return results
+	}
+
+	// Queue everything before the broadcaster runs, one at a time to fix the order of arrival
+	sends := map[string]<-chan *TxResult{}
+	for i, s := range []struct {
+		infoMsg  string
+		priority TxPriority
+		deadline time.Duration
+	}{
+		{"register", TxPriorityNormal, 0},
+		{"payload without deadline", TxPriorityHigh, 0},
+		{"payload due late", TxPriorityHigh, time.Hour},
+		{"stake", TxPriorityNormal, 0},
+		{"payload due soon", TxPriorityHigh, time.Minute},
+	} {
+		sends[s.infoMsg] = send(s.infoMsg, s.priority, s.deadline)
+		require.Eventually(t, func() bool { return b.queued() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	for infoMsg, results := range sends {
+		select {
+		case result := <-results:
+			assert.Equal(t, infoMsg, result.TxHash, "result reported back to its own caller")
+		case <-time.After(time.Second):
+			t.Fatalf("no result for %s", infoMsg)
+		}
+	}
+	assert.Equal(t, []string{"payload due soon", "payload due late", "payload without deadline", "register", "stake"}, broadcastOrder)
+}
+
+func TestTxBroadcasterFailsQueuedMessagesOnStop(t *testing.T) {
+	b := newTestTxBroadcaster(func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error) {
+		t.Fatal("nothing should be broadcast")
+		return nil, nil
+	})
+
+	// Messages whose caller gave up are dropped unsent
+	abandoned, abandon := context.WithCancel(context.Background())
+	abandon()
+	_, err := b.Send(abandoned, &emissionstypes.RegisterRequest{}, "abandoned", TxPriorityHigh)
+	assert.ErrorContains(t, err, "tx aborted while queued")
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := b.Send(context.Background(), &emissionstypes.RegisterRequest{}, "register", TxPriorityNormal)
+		errs <- err
+	}()
+	require.Eventually(t, func() bool { return b.queued() == 2 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.Run(ctx)
+
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "tx broadcaster stopped")
+	case <-time.After(time.Second):
+		t.Fatal("queued message was not failed on stop")
+	}
+	_, err = b.Send(context.Background(), &emissionstypes.RegisterRequest{}, "late", TxPriorityNormal)
+	assert.ErrorContains(t, err, "tx broadcaster stopped")
+}
+
+func TestTxBroadcasterRequeuesPayloadsNeverIncluded(t *testing.T) {
+	var mu sync.Mutex
+	broadcasts := []string{}
+	b := NewTxBroadcaster(&NodeConfig{Confirmation: ConfirmationConfig{MaxRequeues: 1}})
+	b.broadcast = func(ctx context.Context, msg sdktypes.Msg, infoMsg string) (*TxResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		broadcasts = append(broadcasts, infoMsg)
+		return &TxResult{TxHash: infoMsg + " " + strconv.Itoa(len(broadcasts)), BatchSize: 1}, nil
+	}
+	// Only the second tx of the payload gets included
+	b.awaitDelivery = func(ctx context.Context, txHash string) (*TxInclusion, error) {
+		if txHash == "payload 2" {
+			return &TxInclusion{Height: 10, GasUsed: 90000, FeePaid: "1000uallo"}, nil
+		}
+		return nil, errTxNotIncluded
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	result, err := b.Send(context.Background(), &emissionstypes.InsertWorkerPayloadRequest{}, "payload", TxPriorityHigh)
+	require.NoError(t, err)
+	assert.Equal(t, "payload 2", result.TxHash)
+	assert.Equal(t, &TxInclusion{Height: 10, GasUsed: 90000, FeePaid: "1000uallo"}, result.Inclusion)
+
+	// Registration is never sent again, as the first tx may still be included
+	result, err = b.Send(context.Background(), &emissionstypes.RegisterRequest{}, "register", TxPriorityNormal)
+	require.NoError(t, err)
+	assert.Nil(t, result.Inclusion)
+
+	// Payloads are given up on after MaxRequeues
+	b.awaitDelivery = func(ctx context.Context, txHash string) (*TxInclusion, error) { return nil, errTxNotIncluded }
+	_, err = b.Send(context.Background(), &emissionstypes.InsertReputerPayloadRequest{}, "late payload", TxPriorityHigh)
+	assert.True(t, errors.Is(err, errTxNotIncluded))
+	assert.Equal(t, []string{"payload", "payload", "register", "late payload", "late payload"}, broadcasts)
+}
+
+func TestNewTxInclusionDecodesDeliverTxResult(t *testing.T) {
+	inclusion := newTxInclusion(&coretypes.ResultTx{
+		Height: 42,
+		TxResult: abcitypes.ExecTxResult{
+			Code:      0,
+			GasWanted: 120000,
+			GasUsed:   95000,
+			Events: []abcitypes.Event{
+				{Type: "message", Attributes: []abcitypes.EventAttribute{{Key: "action", Value: "/emissions.v4.InsertWorkerPayloadRequest"}}},
+				{Type: "tx", Attributes: []abcitypes.EventAttribute{{Key: "fee", Value: "2400uallo"}, {Key: "fee_payer", Value: "allo1"}}},
+			},
+		},
+	})
+	assert.Equal(t, int64(42), inclusion.Height)
+	assert.Equal(t, int64(120000), inclusion.GasWanted)
+	assert.Equal(t, int64(95000), inclusion.GasUsed)
+	assert.Equal(t, "2400uallo", inclusion.FeePaid)
+	assert.Equal(t, uint32(0), inclusion.Code)
+	assert.Len(t, inclusion.Events, 2)
+}
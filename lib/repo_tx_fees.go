@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtnode "github.com/cosmos/cosmos-sdk/client/grpc/node"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Prices the gas of transactions according to the configured strategy. Estimates gas prices from the
+// minimum gas price of the chain and the gas prices offered by the txs of other accounts in recent blocks.
+// The txs of the node are left out, as their fees include the excess gas correction, retry increases and
+// deadline multiplier, and would make the estimate climb with every inclusion.
+type GasPriceOracle struct {
+	config    FeeConfig
+	gasPrices float64 // configured gas prices, paid by the fixed strategy and until an estimate is known
+	// Minimum gas price of the chain, in the bond denom
+	minGasPrice func(ctx context.Context) (float64, error)
+	// Height of the latest block and the gas prices offered by the txs of other accounts in it
+	blockGasPrices func(ctx context.Context) (BlockHeight, []float64, error)
+
+	mu            sync.Mutex
+	recent        []float64 // gas prices of recently included txs, oldest first
+	chainMin      float64
+	chainMinAt    time.Time
+	sampledAt     time.Time
+	sampledHeight BlockHeight
+}
+
+func NewGasPriceOracle(node *NodeConfig) *GasPriceOracle {
+	o := &GasPriceOracle{
+		config:    node.Fee,
+		gasPrices: node.Wallet.GasPrices,
+		minGasPrice: func(ctx context.Context) (float64, error) {
+			return 0, errors.New("no rpc endpoint to query the minimum gas price from")
+		},
+		blockGasPrices: func(ctx context.Context) (BlockHeight, []float64, error) {
+			return 0, nil, errors.New("no rpc endpoint to query blocks from")
+		},
+	}
+	if node.Chain.Rpc != nil {
+		client := cmtnode.NewServiceClient(node.Chain.Rpc)
+		o.minGasPrice = func(ctx context.Context) (float64, error) {
+			res, err := client.Config(ctx, &cmtnode.ConfigRequest{})
+			if err != nil {
+				return 0, err
+			}
+			return parseGasPrice(res.MinimumGasPrice)
+		}
+		o.blockGasPrices = func(ctx context.Context) (BlockHeight, []float64, error) {
+			res, err := node.Chain.Rpc.Active().Client.RPC.BlockResults(ctx, nil)
+			if err != nil {
+				return 0, nil, err
+			}
+			return res.Height, txGasPrices(res.TxsResults, node.Wallet.Address), nil
+		}
+	}
+	return o
+}
+
+// Gas prices offered by the successful txs, i.e. their fees over their gas limit, leaving out the txs of the address
+func txGasPrices(results []*abcitypes.ExecTxResult, address string) []float64 {
+	prices := []float64{}
+	for _, result := range results {
+		if result.Code != 0 || result.GasWanted <= 0 {
+			continue
+		}
+		fee, own := "", false
+		for _, event := range result.Events {
+			if event.Type != sdktypes.EventTypeTx {
+				continue
+			}
+			for _, attribute := range event.Attributes {
+				switch attribute.Key {
+				case sdktypes.AttributeKeyFee:
+					fee = attribute.Value
+				case sdktypes.AttributeKeyAccountSequence:
+					// "<address>/<sequence>" of each signer
+					own = own || strings.HasPrefix(attribute.Value, address+"/")
+				}
+			}
+		}
+		if own || fee == "" {
+			continue
+		}
+		amount, err := parseGasPrice(fee)
+		if err != nil || amount <= 0 {
+			continue
+		}
+		prices = append(prices, amount/float64(result.GasWanted))
+	}
+	return prices
+}
+
+// Amount of the bond denom in the coins, 0 if they hold none
+func parseGasPrice(coins string) (float64, error) {
+	decCoins, err := sdktypes.ParseDecCoins(coins)
+	if err != nil {
+		return 0, err
+	}
+	return decCoins.AmountOf(DEFAULT_BOND_DENOM).Float64()
+}
+
+// Record the gas price offered by a tx of another account included in a block
+func (o *GasPriceOracle) Observe(gasPrice float64) {
+	if gasPrice <= 0 {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.recent = append(o.recent, gasPrice)
+	if len(o.recent) > o.config.Window() {
+		o.recent = o.recent[len(o.recent)-o.config.Window():]
+	}
+}
+
+// Gas price to pay for a tx due by the deadline of ctx, if any
+func (o *GasPriceOracle) GasPrice(ctx context.Context) float64 {
+	strategy := o.config.FeeStrategy()
+	if strategy == FeeStrategyFixed {
+		return o.gasPrices
+	}
+	price := o.estimate(ctx)
+	if strategy == FeeStrategyAggressiveOnDeadline {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= o.config.DeadlineWindow() {
+			price *= o.config.DeadlineGasPriceMultiplier()
+		}
+	}
+	return price
+}
+
+// Median gas price of recently included txs, at least the minimum gas price of the chain.
+// The configured gas prices until either is known.
+func (o *GasPriceOracle) estimate(ctx context.Context) float64 {
+	chainMin := o.chainMinGasPrice(ctx)
+	o.sampleLatestBlock(ctx)
+
+	o.mu.Lock()
+	recent := append([]float64{}, o.recent...)
+	o.mu.Unlock()
+	if len(recent) == 0 && chainMin == 0 {
+		return o.gasPrices
+	}
+
+	price := 0.0
+	if len(recent) > 0 {
+		sort.Float64s(recent)
+		price = recent[len(recent)/2]
+		if len(recent)%2 == 0 {
+			price = (recent[len(recent)/2-1] + recent[len(recent)/2]) / 2
+		}
+	}
+	if price < chainMin {
+		price = chainMin
+	}
+	return price
+}
+
+// Observe the gas prices of the txs of the latest block, if not sampled yet, at most every DEFAULT_FEE_ORACLE_SAMPLE_SECONDS
+func (o *GasPriceOracle) sampleLatestBlock(ctx context.Context) {
+	o.mu.Lock()
+	if time.Since(o.sampledAt) < DEFAULT_FEE_ORACLE_SAMPLE_SECONDS*time.Second {
+		o.mu.Unlock()
+		return
+	}
+	o.sampledAt = time.Now()
+	o.mu.Unlock()
+
+	height, prices, err := o.blockGasPrices(ctx)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not query gas prices of the latest block")
+		return
+	}
+	o.mu.Lock()
+	if height == o.sampledHeight {
+		o.mu.Unlock()
+		return
+	}
+	o.sampledHeight = height
+	o.mu.Unlock()
+	for _, price := range prices {
+		o.Observe(price)
+	}
+}
+
+// Minimum gas price of the chain, queried at most every DEFAULT_FEE_ORACLE_REFRESH_SECONDS.
+// Keeps the last known value, 0 at first, if the query fails.
+func (o *GasPriceOracle) chainMinGasPrice(ctx context.Context) float64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if time.Since(o.chainMinAt) < DEFAULT_FEE_ORACLE_REFRESH_SECONDS*time.Second {
+		return o.chainMin
+	}
+	o.chainMinAt = time.Now()
+	chainMin, err := o.minGasPrice(ctx)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not query minimum gas price of the chain")
+		return o.chainMin
+	}
+	o.chainMin = chainMin
+	return o.chainMin
+}
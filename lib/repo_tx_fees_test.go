@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGasPriceOracle(config FeeConfig, gasPrices float64, chainMin float64, chainErr error) *GasPriceOracle {
+	o := NewGasPriceOracle(&NodeConfig{Fee: config, Wallet: WalletConfig{GasPrices: gasPrices}})
+	o.minGasPrice = func(ctx context.Context) (float64, error) { return chainMin, chainErr }
+	return o
+}
+
+func TestGasPriceOracleFixedPaysConfiguredGasPrices(t *testing.T) {
+	o := newTestGasPriceOracle(FeeConfig{}, 10, 1, nil)
+	o.Observe(5)
+	assert.Equal(t, 10.0, o.GasPrice(context.Background()))
+}
+
+func TestGasPriceOracleEstimatesFromChainAndIncludedTxs(t *testing.T) {
+	o := newTestGasPriceOracle(FeeConfig{Strategy: FeeStrategyOracle, OracleWindow: 3}, 10, 2, nil)
+	assert.Equal(t, 2.0, o.GasPrice(context.Background()), "minimum gas price of the chain until txs are included")
+
+	for _, gasPrice := range []float64{9, 3, 4, 5} {
+		o.Observe(gasPrice)
+	}
+	assert.Equal(t, 4.0, o.GasPrice(context.Background()), "median of the last 3 included txs")
+
+	o = newTestGasPriceOracle(FeeConfig{Strategy: FeeStrategyOracle}, 10, 0, errors.New("unavailable"))
+	assert.Equal(t, 10.0, o.GasPrice(context.Background()), "configured gas prices until an estimate is known")
+}
+
+func TestGasPriceOraclePaysMoreCloseToDeadline(t *testing.T) {
+	o := newTestGasPriceOracle(FeeConfig{Strategy: FeeStrategyAggressiveOnDeadline, DeadlineSeconds: 20, DeadlineMultiplier: 3}, 10, 2, nil)
+	assert.Equal(t, 2.0, o.GasPrice(context.Background()))
+
+	due, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.Equal(t, 2.0, o.GasPrice(due))
+
+	due, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.Equal(t, 6.0, o.GasPrice(due))
+}
+
+func TestGasPriceOracleFollowsGasPricesOfRecentBlocks(t *testing.T) {
+	o := newTestGasPriceOracle(FeeConfig{Strategy: FeeStrategyOracle, OracleWindow: 3}, 10, 2, nil)
+	blocks := []struct {
+		height BlockHeight
+		prices []float64
+	}{{100, []float64{3, 3}}, {100, []float64{3, 3}}, {101, []float64{5, 6, 7}}}
+	sampled := 0
+	o.blockGasPrices = func(ctx context.Context) (BlockHeight, []float64, error) {
+		block := blocks[sampled]
+		sampled++
+		return block.height, block.prices, nil
+	}
+
+	assert.Equal(t, 3.0, o.GasPrice(context.Background()))
+	o.sampledAt = time.Time{}
+	assert.Equal(t, 3.0, o.GasPrice(context.Background()), "same block sampled once")
+	assert.Equal(t, 2, sampled)
+	assert.Equal(t, 3.0, o.GasPrice(context.Background()), "latest block sampled at most every few seconds")
+	assert.Equal(t, 2, sampled)
+
+	o.sampledAt = time.Time{}
+	assert.Equal(t, 6.0, o.GasPrice(context.Background()), "estimate rises with the market")
+}
+
+func TestTxGasPricesLeavesOutOwnAndFailedTxs(t *testing.T) {
+	txEvent := func(fee string, accSeq string) []abcitypes.Event {
+		return []abcitypes.Event{{Type: "tx", Attributes: []abcitypes.EventAttribute{
+			{Key: "fee", Value: fee},
+			{Key: "acc_seq", Value: accSeq},
+		}}}
+	}
+	results := []*abcitypes.ExecTxResult{
+		{GasWanted: 100000, Events: txEvent("300000uallo", "allo1other/4")},
+		{GasWanted: 100000, Events: txEvent("900000uallo", "allo1node/7")},
+		{GasWanted: 100000, Code: 5, Events: txEvent("800000uallo", "allo1other/5")},
+		{GasWanted: 200000, Events: txEvent("", "allo1free/1")},
+	}
+	assert.Equal(t, []float64{3}, txGasPrices(results, "allo1node"))
+}
+
+func TestParseGasPrice(t *testing.T) {
+	price, err := parseGasPrice("0.5uallo,1stake")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, price)
+
+	price, err = parseGasPrice("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, price)
+}
@@ -13,9 +13,9 @@ import (
 	"github.com/rs/zerolog/log"
 
 	errorsmod "cosmossdk.io/errors"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
 	sdktypes "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
-	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
 )
 
 const ERROR_MESSAGE_DATA_ALREADY_SUBMITTED = "already submitted"
@@ -29,6 +29,18 @@ const ERROR_PROCESSING_CONTINUE = "continue"
 const ERROR_PROCESSING_OK = "ok"
 const ERROR_PROCESSING_ERROR = "error"
 
+// sleepWithContext waits for the given delay, returning early with the context error if ctx is done first
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // calculateExponentialBackoffDelay returns a duration based on retry count and base delay
 func calculateExponentialBackoffDelay(baseDelay int64, retryCount int64) time.Duration {
 	return time.Duration(math.Pow(float64(baseDelay), float64(retryCount))) * time.Second
@@ -39,7 +51,7 @@ func calculateExponentialBackoffDelay(baseDelay int64, retryCount int64) time.Du
 // - "continue", nil: tx was not successful, but special error type. Handled, ready for retry
 // - "ok", nil: tx was successful
 // - "error", error: tx failed, with regular error type
-func processError(err error, infoMsg string, retryCount int64, node *NodeConfig) (string, error) {
+func processError(ctx context.Context, err error, infoMsg string, retryCount int64, node *NodeConfig) (string, error) {
 	if strings.Contains(err.Error(), ERROR_MESSAGE_ABCI_ERROR_CODE_MARKER) {
 		re := regexp.MustCompile(`error code: '(\d+)'`)
 		matches := re.FindStringSubmatch(err.Error())
@@ -56,7 +68,7 @@ func processError(err error, infoMsg string, retryCount int64, node *NodeConfig)
 						Err(err).
 						Str("msg", infoMsg).
 						Msg("Mempool is full, retrying with exponential backoff")
-					time.Sleep(delay)
+					_ = sleepWithContext(ctx, delay)
 					return ERROR_PROCESSING_CONTINUE, nil
 				case int(sdkerrors.ErrWrongSequence.ABCICode()), int(sdkerrors.ErrInvalidSequence.ABCICode()):
 					log.Warn().
@@ -65,7 +77,7 @@ func processError(err error, infoMsg string, retryCount int64, node *NodeConfig)
 						Int64("delay", node.Wallet.AccountSequenceRetryDelay).
 						Msg("Account sequence mismatch detected, retrying with fixed delay")
 					// Wait a fixed block-related waiting time
-					time.Sleep(time.Duration(node.Wallet.AccountSequenceRetryDelay) * time.Second)
+					_ = sleepWithContext(ctx, time.Duration(node.Wallet.AccountSequenceRetryDelay)*time.Second)
 					return ERROR_PROCESSING_CONTINUE, nil
 				case int(sdkerrors.ErrInsufficientFee.ABCICode()):
 					log.Warn().Str("msg", infoMsg).Msg("Insufficient fee")
@@ -92,7 +104,7 @@ func processError(err error, infoMsg string, retryCount int64, node *NodeConfig)
 			Str("msg", infoMsg).
 			Int64("delay", node.Wallet.AccountSequenceRetryDelay).
 			Msg("Account sequence mismatch detected, re-fetching sequence")
-		time.Sleep(time.Duration(node.Wallet.AccountSequenceRetryDelay) * time.Second)
+		_ = sleepWithContext(ctx, time.Duration(node.Wallet.AccountSequenceRetryDelay)*time.Second)
 		return ERROR_PROCESSING_CONTINUE, nil
 	} else if strings.Contains(err.Error(), ERROR_MESSAGE_WAITING_FOR_NEXT_BLOCK) {
 		log.Warn().Str("msg", infoMsg).Msg("Tx accepted in mempool, it will be included in the following block(s) - not retrying")
@@ -105,104 +117,78 @@ func processError(err error, infoMsg string, retryCount int64, node *NodeConfig)
 	return ERROR_PROCESSING_ERROR, errorsmod.Wrapf(err, "failed to process error")
 }
 
-// SendDataWithRetry attempts to send data, handling retries, with fee awareness.
-// Custom handling for different errors.
-func (node *NodeConfig) SendDataWithRetry(ctx context.Context, req sdktypes.Msg, infoMsg string) (*cosmosclient.Response, error) {
-	var txResp *cosmosclient.Response
-	// Excess fees correction factor translated to fees using configured gas prices
-	excessFactorFees := float64(EXCESS_CORRECTION_IN_GAS) * node.Wallet.GasPrices
-
-	for retryCount := int64(0); retryCount <= node.Wallet.MaxRetries; retryCount++ {
-		log.Debug().Msgf("SendDataWithRetry iteration started (%d/%d)", retryCount, node.Wallet.MaxRetries)
-		txOptions := cosmosclient.TxOptions{}
-		txService, err := node.Chain.Client.CreateTxWithOptions(ctx, node.Chain.Account, txOptions, req)
-		fmt.Println("CreateTxWithOptions---------------》》》》,error: %+v, is nil : %v", err, err != nil)
-		if err != nil {
-			log.Info().Str("error", err.Error()).Str("msg", infoMsg).Msg("CreateTxWithOptions---------------》》》》")
-			// Handle error on creation of tx, before broadcasting
-			if strings.Contains(err.Error(), ERROR_MESSAGE_ACCOUNT_SEQUENCE_MISMATCH) {
-				log.Warn().Err(err).Str("msg", infoMsg).Msg("Account sequence mismatch detected, resetting sequence")
-				expectedSeqNum, currentSeqNum, err := parseSequenceFromAccountMismatchError(err.Error())
-				if err != nil {
-					log.Error().Err(err).Str("msg", infoMsg).Msg("Failed to parse sequence from error - retrying with regular delay")
-					time.Sleep(time.Duration(node.Wallet.RetryDelay) * time.Second)
-					continue
-				}
-				// Reset sequence to expected in the client's tx factory
-				node.Chain.Client.TxFactory = node.Chain.Client.TxFactory.WithSequence(expectedSeqNum)
-				log.Info().Uint64("expected", expectedSeqNum).Uint64("current", currentSeqNum).Msg("Retrying resetting sequence from current to expected")
-				txService, err = node.Chain.Client.CreateTxWithOptions(ctx, node.Chain.Account, txOptions, req)
-				if err != nil {
-					return nil, errorsmod.Wrapf(err, "failed to reset sequence second time, exiting")
-				}
-			} else {
-				errorResponse, err := processError(err, infoMsg, retryCount, node)
-				switch errorResponse {
-				case ERROR_PROCESSING_OK:
-					return txResp, nil
-				case ERROR_PROCESSING_ERROR:
-					// if error has not been handled, sleep and retry with regular delay
-					if err != nil {
-						log.Error().Err(err).Str("msg", infoMsg).Msgf("Failed, retrying... (Retry %d/%d)", retryCount, node.Wallet.MaxRetries)
-						// Wait for the uniform delay before retrying
-						time.Sleep(time.Duration(node.Wallet.RetryDelay) * time.Second)
-						continue
-					}
-				case ERROR_PROCESSING_CONTINUE:
-					// Error has not been handled, just continue next iteration
-					continue
-				default:
-					return nil, errorsmod.Wrapf(err, "failed to process error")
-				}
-			}
-		} else {
-			log.Trace().Msg("Create tx with account sequence OK")
-		}
+// Transaction broadcast by SendDataWithRetry
+type TxResult struct {
+	TxHash    string
+	Fees      string       // fees offered for the whole tx, empty if no gas prices are configured
+	MsgIndex  int          // index of the message in the tx
+	BatchSize int          // messages in the tx, more than 1 if batched with other payloads
+	Inclusion *TxInclusion // nil until the tx is confirmed included in a block
+}
 
-		// Handle fees if necessary
-		if node.Wallet.GasPrices > 0 {
-			// Precalculate fees
-			fees := uint64(float64(txService.Gas()+EXCESS_CORRECTION_IN_GAS) * node.Wallet.GasPrices)
-			// Add excess fees correction factor to increase with each retry
-			fees = fees + uint64(float64(retryCount+1)*excessFactorFees)
-			// Limit fees to maxFees
-			if fees > node.Wallet.MaxFees {
-				log.Warn().Uint64("gas", txService.Gas()).Uint64("limit", node.Wallet.MaxFees).Msg("Gas limit exceeded, using maxFees instead")
-				fees = node.Wallet.MaxFees
-			}
-			txOptions := cosmosclient.TxOptions{
-				Fees: fmt.Sprintf("%duallo", fees),
-			}
-			log.Info().Str("fees", txOptions.Fees).Msg("Attempting tx with calculated fees")
-			txService, err = node.Chain.Client.CreateTxWithOptions(ctx, node.Chain.Account, txOptions, req)
-			fmt.Println("CreateTxWithOptions---------------》》》》,error: %+v, txOptions.Fees : %v", err, txOptions.Fees)
-			if err != nil {
-				return nil, err
-			}
+// Inclusion of a transaction in a block, as decoded from its DeliverTx result
+type TxInclusion struct {
+	Height    int64
+	GasWanted int64
+	GasUsed   int64
+	FeePaid   string // fees deducted from the account, from the tx events
+	Code      uint32 // ABCI result code, 0 on success
+	Codespace string
+	Log       string
+	Events    []abcitypes.Event
+}
+
+var errNoBroadcaster = errors.New("no tx broadcaster configured")
+
+// The tx was accepted into the mempool but not included in a block in time
+var errTxNotIncluded = errors.New("tx not included in a block")
+
+// SendDataWithRetry queues the message with the tx broadcaster at normal priority and waits for its result.
+// The result is nil if the tx was not broadcast by this call, e.g. when the data was already submitted.
+func (node *NodeConfig) SendDataWithRetry(ctx context.Context, req sdktypes.Msg, infoMsg string) (*TxResult, error) {
+	return node.SendDataWithPriority(ctx, req, infoMsg, TxPriorityNormal)
+}
+
+// SendDataWithPriority queues the message with the tx broadcaster at the given priority and waits for its result
+func (node *NodeConfig) SendDataWithPriority(ctx context.Context, req sdktypes.Msg, infoMsg string, priority TxPriority) (*TxResult, error) {
+	if node.Broadcaster == nil {
+		return nil, errNoBroadcaster
+	}
+	return node.Broadcaster.Send(ctx, req, infoMsg, priority)
+}
+
+// sendWithRetry attempts to send data, handling retries, with fee awareness.
+// Custom handling for different errors.
+func (b *TxBroadcaster) sendWithRetry(ctx context.Context, req sdktypes.Msg, infoMsg string) (*TxResult, error) {
+	for retryCount := int64(0); retryCount <= b.node.Wallet.MaxRetries; retryCount++ {
+		// Stop retrying once the caller gave up, e.g. on shutdown
+		if err := ctx.Err(); err != nil {
+			return nil, errorsmod.Wrapf(err, "tx aborted: %s", infoMsg)
 		}
+		log.Debug().Msgf("SendDataWithRetry iteration started (%d/%d)", retryCount, b.node.Wallet.MaxRetries)
 
-		// Broadcast tx
-		txResponse, err := txService.Broadcast(ctx)
-		fmt.Println("Broadcast tx---------------》》》》, txResponse.TxHash : %v", err, txResponse.TxHash)
+		result, err := b.broadcastOnce(ctx, retryCount, 0, req)
 		if err == nil {
-			log.Info().Str("msg", infoMsg).Str("txHash", txResponse.TxHash).Msg("Success")
-			return txResp, nil
+			log.Info().Str("msg", infoMsg).Str("txHash", result.TxHash).Str("fees", result.Fees).Msg("Success")
+			return result, nil
 		}
-		// Handle error on broadcasting
-		errorResponse, err := processError(err, infoMsg, retryCount, node)
+		b.reconcileSequence(err)
+
+		// Handle error on creating or broadcasting the tx
+		errorResponse, err := processError(ctx, err, infoMsg, retryCount, b.node)
 		switch errorResponse {
 		case ERROR_PROCESSING_OK:
-			return txResp, nil
+			return nil, nil
 		case ERROR_PROCESSING_ERROR:
 			// Error has not been handled, sleep and retry with regular delay
 			if err != nil {
-				log.Error().Err(err).Str("msg", infoMsg).Msgf("Failed, retrying... (Retry %d/%d)", retryCount, node.Wallet.MaxRetries)
+				log.Error().Err(err).Str("msg", infoMsg).Msgf("Failed, retrying... (Retry %d/%d)", retryCount, b.node.Wallet.MaxRetries)
 				// Wait for the uniform delay before retrying
-				time.Sleep(time.Duration(node.Wallet.RetryDelay) * time.Second)
+				_ = sleepWithContext(ctx, time.Duration(b.node.Wallet.RetryDelay)*time.Second)
 				continue
 			}
 		case ERROR_PROCESSING_CONTINUE:
-			// Error has not been handled, just continue next iteration
+			// Error has been handled, just continue next iteration
 			continue
 		default:
 			return nil, errorsmod.Wrapf(err, "failed to process error")
@@ -3,61 +3,105 @@ package main
 import (
 	"allora_offchain_node/lib"
 	usecase "allora_offchain_node/usecase"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
 )
 
 func ConvertEntrypointsToInstances(userConfig lib.UserConfig) error {
-	/// Initialize adapters using the factory function
-	for i, worker := range userConfig.Worker {
-		if worker.InferenceEntrypointName != "" {
-			adapter, err := NewAlloraAdapter(worker.InferenceEntrypointName)
-			if err != nil {
-				fmt.Println("Error creating inference adapter:", err)
-				return err
-			}
-			userConfig.Worker[i].InferenceEntrypoint = adapter
+	/// Initialize adapters from the adapter registry
+	for i := range userConfig.Worker {
+		if err := convertWorkerEntrypoints(&userConfig.Worker[i]); err != nil {
+			return err
 		}
+	}
 
-		if worker.ForecastEntrypointName != "" {
-			adapter, err := NewAlloraAdapter(worker.ForecastEntrypointName)
-			if err != nil {
-				fmt.Println("Error creating forecast adapter:", err)
-				return err
-			}
-			userConfig.Worker[i].ForecastEntrypoint = adapter
+	for i := range userConfig.Reputer {
+		if err := convertReputerEntrypoints(&userConfig.Reputer[i]); err != nil {
+			return err
 		}
 	}
 
-	for i, reputer := range userConfig.Reputer {
-		if reputer.GroundTruthEntrypointName != "" {
-			adapter, err := NewAlloraAdapter(reputer.GroundTruthEntrypointName)
-			if err != nil {
-				fmt.Println("Error creating reputer adapter:", err)
-				return err
-			}
-			userConfig.Reputer[i].GroundTruthEntrypoint = adapter
+	if worker := userConfig.TopicDiscovery.WorkerTemplate; worker != nil {
+		if err := convertWorkerEntrypoints(worker); err != nil {
+			return fmt.Errorf("topic discovery: %w", err)
 		}
 	}
 
-	for i, reputer := range userConfig.Reputer {
-		if reputer.LossFunctionEntrypointName != "" {
-			adapter, err := NewAlloraAdapter(reputer.LossFunctionEntrypointName)
-			if err != nil {
-				fmt.Println("Error creating reputer adapter:", err)
-				return err
-			}
-			userConfig.Reputer[i].LossFunctionEntrypoint = adapter
+	if reputer := userConfig.TopicDiscovery.ReputerTemplate; reputer != nil {
+		if err := convertReputerEntrypoints(reputer); err != nil {
+			return fmt.Errorf("topic discovery: %w", err)
 		}
 	}
 	return nil
 }
 
+func convertWorkerEntrypoints(worker *lib.WorkerConfig) error {
+	if worker.InferenceEntrypointName != "" {
+		adapter, err := lib.ResolveInferer(worker.InferenceEntrypointName, worker.Parameters)
+		if err != nil {
+			return fmt.Errorf("error creating inference adapter: %w", err)
+		}
+		worker.InferenceEntrypoint = adapter
+	}
+
+	if worker.ForecastEntrypointName != "" {
+		adapter, err := lib.ResolveForecaster(worker.ForecastEntrypointName, worker.Parameters)
+		if err != nil {
+			return fmt.Errorf("error creating forecast adapter: %w", err)
+		}
+		worker.ForecastEntrypoint = adapter
+	}
+
+	if guards := &worker.InferenceGuards; guards.FallbackEntrypointName != "" {
+		adapter, err := lib.ResolveInferer(guards.FallbackEntrypointName, guards.FallbackEntrypointParameters(*worker))
+		if err != nil {
+			return fmt.Errorf("error creating fallback inference adapter: %w", err)
+		}
+		guards.FallbackEntrypoint = adapter
+	}
+	return nil
+}
+
+func convertReputerEntrypoints(reputer *lib.ReputerConfig) error {
+	if reputer.GroundTruthEntrypointName != "" {
+		adapter, err := lib.ResolveGroundTruthSource(reputer.GroundTruthEntrypointName, reputer.GroundTruthParameters)
+		if err != nil {
+			return fmt.Errorf("error creating ground truth adapter: %w", err)
+		}
+		reputer.GroundTruthEntrypoint = adapter
+	}
+
+	if reputer.LossFunctionEntrypointName != "" {
+		adapter, err := lib.ResolveLossFunction(reputer.LossFunctionEntrypointName, reputer.LossFunctionParameters.AdapterParameters())
+		if err != nil {
+			return fmt.Errorf("error creating loss function adapter: %w", err)
+		}
+		reputer.LossFunctionEntrypoint = adapter
+	}
+	return nil
+}
+
 func main() {
+	listAdapters := flag.Bool("list-adapters", false, "list the available adapters with their parameters and exit")
+	flag.Parse()
+	if *listAdapters {
+		if err := PrintAdapters(os.Stdout); err != nil {
+			fmt.Println("Error listing adapters:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	initLogger()
 	if dotErr := godotenv.Load(); dotErr != nil {
 		log.Info().Msg("Unable to load .env file")
@@ -65,9 +109,13 @@ func main() {
 
 	log.Info().Msg("Starting allora offchain node...")
 
+	// Root context of the node, cancelled on SIGINT/SIGTERM to shut all processes down
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	metrics := lib.NewMetrics(lib.COUNTER_DATA)
 	metrics.RegisterMetricsCounters()
-	metrics.StartMetricsServer(":2112")
+	metricsServer := metrics.StartMetricsServer(":2112")
 
 	finalUserConfig := lib.UserConfig{}
 	alloraJsonConfig := os.Getenv(lib.ALLORA_OFFCHAIN_NODE_CONFIG_JSON)
@@ -101,6 +149,7 @@ func main() {
 	}
 
 	// Convert entrypoints to instances of adapters
+	lib.ConfigureAdapterHttpClient(finalUserConfig.AdapterHttp)
 	err := ConvertEntrypointsToInstances(finalUserConfig)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to convert Entrypoints to instances of adapters")
@@ -114,5 +163,18 @@ func main() {
 	usecase.GetLocalIP()
 	usecase.ReadFile()
 	spawner.Metrics = *metrics
-	spawner.Spawn()
+	// Expose the state of the worker and reputer processes next to the metrics
+	http.Handle("/health", spawner.Supervisor)
+	spawner.Spawn(ctx)
+	if err := spawner.Ledger.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to close submission ledger")
+	}
+	if err := lib.CloseAdapters(); err != nil {
+		log.Error().Err(err).Msg("Failed to close adapters")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), lib.METRICS_SERVER_SHUTDOWN_SECONDS*time.Second)
+	defer cancel()
+	metrics.StopMetricsServer(shutdownCtx, metricsServer)
+	log.Info().Msg("Allora offchain node stopped")
 }
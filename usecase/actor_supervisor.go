@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog/log"
+)
+
+type ActorRole string
+
+const (
+	ActorRoleWorker  ActorRole = "worker"
+	ActorRoleReputer ActorRole = "reputer"
+)
+
+type ActorState string
+
+const (
+	ActorStateRegistering ActorState = "registering"
+	ActorStateStaking     ActorState = "staking"
+	ActorStateRunning     ActorState = "running"
+	ActorStateBackingOff  ActorState = "backing-off"
+)
+
+// Snapshot of a supervised actor, as exposed for health checks
+type ActorStatus struct {
+	Role      ActorRole              `json:"role"`
+	TopicId   emissionstypes.TopicId `json:"topicId"`
+	State     ActorState             `json:"state"`
+	Restarts  int                    `json:"restarts"`
+	LastError string                 `json:"lastError,omitempty"`
+	Since     time.Time              `json:"since"`
+}
+
+// Restarts worker and reputer processes that exited or panicked, with exponential backoff,
+// and keeps track of the state of each of them
+type ActorSupervisor struct {
+	mu             sync.RWMutex
+	actors         map[string]*ActorStatus
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func NewActorSupervisor(config lib.SupervisorConfig) *ActorSupervisor {
+	return &ActorSupervisor{
+		actors:         make(map[string]*ActorStatus),
+		initialBackoff: config.InitialBackoff(),
+		maxBackoff:     config.MaxBackoff(),
+	}
+}
+
+func actorKey(role ActorRole, topicId emissionstypes.TopicId) string {
+	return fmt.Sprintf("%s/%d", role, topicId)
+}
+
+// Handle through which a supervised actor records its state. It only ever updates the status of the actor
+// it was given to, so an actor superseded by one started since for the same role and topic leaves that one's alone.
+// A nil handle ignores the calls.
+type SupervisedActor struct {
+	supervisor *ActorSupervisor
+	status     *ActorStatus
+}
+
+// Run the actor until ctx is cancelled, restarting it whenever it returns or panics
+func (s *ActorSupervisor) Supervise(ctx context.Context, role ActorRole, topicId emissionstypes.TopicId, run func(ctx context.Context, actor *SupervisedActor) error) {
+	key := actorKey(role, topicId)
+	status := &ActorStatus{Role: role, TopicId: topicId, State: ActorStateRegistering, Since: time.Now()}
+	s.mu.Lock()
+	s.actors[key] = status
+	s.mu.Unlock()
+	actor := &SupervisedActor{supervisor: s, status: status}
+	defer func() {
+		// An actor started since for the same role and topic keeps its status
+		s.mu.Lock()
+		if s.actors[key] == status {
+			delete(s.actors, key)
+		}
+		s.mu.Unlock()
+	}()
+
+	restarts := 0
+	for {
+		startedAt := time.Now()
+		err := runRecovered(ctx, actor, run)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("actor exited")
+		}
+
+		// An actor that ran for longer than the maximum backoff is considered to have recovered
+		if time.Since(startedAt) > s.maxBackoff {
+			restarts = 0
+		}
+		delay := s.backoff(restarts)
+		restarts++
+
+		s.mu.Lock()
+		status.State = ActorStateBackingOff
+		status.Restarts++
+		status.LastError = err.Error()
+		status.Since = time.Now()
+		s.mu.Unlock()
+		log.Warn().Err(err).Str("role", string(role)).Uint64("topicId", topicId).Str("delay", delay.String()).Msg("Actor stopped, restarting after backoff")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		actor.SetState(ActorStateRegistering)
+	}
+}
+
+// Run the actor, turning a panic into an error
+func runRecovered(ctx context.Context, actor *SupervisedActor, run func(ctx context.Context, actor *SupervisedActor) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("actor panicked: %v", r)
+		}
+	}()
+	return run(ctx, actor)
+}
+
+// Exponential backoff from the initial backoff, capped at the maximum backoff
+func (s *ActorSupervisor) backoff(restarts int) time.Duration {
+	delay := s.initialBackoff
+	for i := 0; i < restarts && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+	return delay
+}
+
+// Record the state of the supervised actor
+func (a *SupervisedActor) SetState(state ActorState) {
+	if a == nil {
+		return
+	}
+	a.supervisor.mu.Lock()
+	defer a.supervisor.mu.Unlock()
+	if a.status.State != state {
+		a.status.State = state
+		a.status.Since = time.Now()
+	}
+}
+
+// Snapshot of all supervised actors, ordered by role and topic
+func (s *ActorSupervisor) Statuses() []ActorStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := make([]ActorStatus, 0, len(s.actors))
+	for _, status := range s.actors {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Role != statuses[j].Role {
+			return statuses[i].Role < statuses[j].Role
+		}
+		return statuses[i].TopicId < statuses[j].TopicId
+	})
+	return statuses
+}
+
+// Serve the actor states as JSON for health checks.
+// Responds 503 if any actor is backing off after a failure, else 200.
+func (s *ActorSupervisor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	statuses := s.Statuses()
+	healthy := true
+	for _, status := range statuses {
+		if status.State == ActorStateBackingOff {
+			healthy = false
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"healthy": healthy, "actors": statuses}); err != nil {
+		log.Error().Err(err).Msg("Could not write health check response")
+	}
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestActorSupervisor() *ActorSupervisor {
+	return &ActorSupervisor{
+		actors:         make(map[string]*ActorStatus),
+		initialBackoff: time.Millisecond,
+		maxBackoff:     10 * time.Millisecond,
+	}
+}
+
+func TestActorSupervisorBackoff(t *testing.T) {
+	supervisor := &ActorSupervisor{initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+
+	assert.Equal(t, time.Second, supervisor.backoff(0))
+	assert.Equal(t, 2*time.Second, supervisor.backoff(1))
+	assert.Equal(t, 8*time.Second, supervisor.backoff(3))
+	assert.Equal(t, 10*time.Second, supervisor.backoff(4))
+	assert.Equal(t, 10*time.Second, supervisor.backoff(100))
+}
+
+func TestActorSupervisorRestartsFailedAndPanickedActors(t *testing.T) {
+	supervisor := newTestActorSupervisor()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	running := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		supervisor.Supervise(ctx, ActorRoleWorker, 1, func(ctx context.Context, actor *SupervisedActor) error {
+			switch runs.Add(1) {
+			case 1:
+				return errors.New("failed to register worker for topic")
+			case 2:
+				panic("boom")
+			default:
+				actor.SetState(ActorStateRunning)
+				close(running)
+				<-ctx.Done()
+				return nil
+			}
+		})
+	}()
+
+	select {
+	case <-running:
+	case <-time.After(time.Second):
+		t.Fatal("actor was not restarted")
+	}
+
+	statuses := supervisor.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, ActorRoleWorker, statuses[0].Role)
+	assert.Equal(t, ActorStateRunning, statuses[0].State)
+	assert.Equal(t, 2, statuses[0].Restarts)
+	assert.Contains(t, statuses[0].LastError, "actor panicked: boom")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("supervisor did not stop on cancellation")
+	}
+	assert.Empty(t, supervisor.Statuses())
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestActorSupervisorKeepsStatusOfActorRestartedForSameTopic(t *testing.T) {
+	supervisor := newTestActorSupervisor()
+	oldCtx, stopOld := context.WithCancel(context.Background())
+	oldDone := make(chan struct{})
+	oldRunning := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer close(oldDone)
+		supervisor.Supervise(oldCtx, ActorRoleWorker, 1, func(ctx context.Context, actor *SupervisedActor) error {
+			close(oldRunning)
+			<-ctx.Done()
+			// Still winding down once the new actor is up
+			<-release
+			actor.SetState(ActorStateRegistering)
+			return nil
+		})
+	}()
+	<-oldRunning
+
+	// Topic discovery stops the actor and starts a new one for the same topic
+	stopOld()
+	newCtx, stopNew := context.WithCancel(context.Background())
+	defer stopNew()
+	newRunning := make(chan struct{})
+	go supervisor.Supervise(newCtx, ActorRoleWorker, 1, func(ctx context.Context, actor *SupervisedActor) error {
+		actor.SetState(ActorStateRunning)
+		close(newRunning)
+		<-ctx.Done()
+		return nil
+	})
+	<-newRunning
+	close(release)
+	<-oldDone
+
+	statuses := supervisor.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, ActorStateRunning, statuses[0].State, "not set by the superseded actor")
+}
+
+func TestActorSupervisorHealthCheck(t *testing.T) {
+	supervisor := newTestActorSupervisor()
+	worker := &SupervisedActor{supervisor: supervisor, status: &ActorStatus{Role: ActorRoleWorker, TopicId: 1, State: ActorStateRunning}}
+	supervisor.actors[actorKey(ActorRoleWorker, 1)] = worker.status
+	supervisor.actors[actorKey(ActorRoleReputer, 1)] = &ActorStatus{Role: ActorRoleReputer, TopicId: 1, State: ActorStateStaking}
+
+	recorder := httptest.NewRecorder()
+	supervisor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"state":"staking"`)
+
+	worker.SetState(ActorStateBackingOff)
+	recorder = httptest.NewRecorder()
+	supervisor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"healthy":false`)
+}
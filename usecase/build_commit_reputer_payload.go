@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	errorsmod "cosmossdk.io/errors"
 	alloraMath "github.com/allora-network/allora-chain/math"
@@ -18,10 +19,8 @@ import (
 // Get the reputer's values at the block from the chain
 // Compute loss bundle with the reputer provided Loss function and ground truth
 // sign and commit to chain
-func (suite *UseCaseSuite) BuildCommitReputerPayload(reputer lib.ReputerConfig, nonce lib.BlockHeight) error {
-	ctx := context.Background()
-
-	valueBundle, err := suite.Node.GetReputerValuesAtBlock(reputer.TopicId, nonce)
+func (suite *UseCaseSuite) BuildCommitReputerPayload(ctx context.Context, reputer lib.ReputerConfig, nonce lib.BlockHeight) error {
+	valueBundle, err := suite.Node.GetReputerValuesAtBlock(ctx, reputer.TopicId, nonce)
 	if err != nil {
 		return errorsmod.Wrapf(err, "error getting reputer values, topic: %d, blockHeight: %d", reputer.TopicId, nonce)
 	}
@@ -30,13 +29,13 @@ func (suite *UseCaseSuite) BuildCommitReputerPayload(reputer lib.ReputerConfig,
 	}
 	valueBundle.Reputer = suite.Node.Wallet.Address
 
-	sourceTruth, err := reputer.GroundTruthEntrypoint.GroundTruth(reputer, nonce)
+	sourceTruth, err := reputer.GroundTruthEntrypoint.GroundTruth(ctx, reputer, nonce)
 	if err != nil {
 		return errorsmod.Wrapf(err, "error getting source truth from reputer, topicId: %d, blockHeight: %d", reputer.TopicId, nonce)
 	}
 	suite.Metrics.IncrementMetricsCounter(lib.TruthRequestCount, suite.Node.Chain.Address, reputer.TopicId)
 
-	lossBundle, err := suite.ComputeLossBundle(sourceTruth, valueBundle, reputer)
+	lossBundle, err := suite.ComputeLossBundle(ctx, sourceTruth, valueBundle, reputer)
 	if err != nil {
 		return errorsmod.Wrapf(err, "error computing loss bundle, topic: %d, blockHeight: %d", reputer.TopicId, nonce)
 	}
@@ -62,7 +61,7 @@ func (suite *UseCaseSuite) BuildCommitReputerPayload(reputer lib.ReputerConfig,
 		log.Info().Uint64("topicId", reputer.TopicId).Msgf("Sending InsertReputerPayload to chain %s", string(reqJSON))
 	}
 	if suite.Node.Wallet.SubmitTx {
-		_, err = suite.Node.SendDataWithRetry(ctx, req, "Send Reputer Data to chain")
+		err = suite.sendPayload(ctx, ActorRoleReputer, reputer.TopicId, nonce, req, "Send Reputer Data to chain")
 		if err != nil {
 			return errorsmod.Wrapf(err, "error sending Reputer Data to chain, topic: %d, blockHeight: %d", reputer.TopicId, nonce)
 		}
@@ -74,7 +73,7 @@ func (suite *UseCaseSuite) BuildCommitReputerPayload(reputer lib.ReputerConfig,
 	return nil
 }
 
-func (suite *UseCaseSuite) ComputeLossBundle(sourceTruth string, vb *emissionstypes.ValueBundle, reputer lib.ReputerConfig) (emissionstypes.ValueBundle, error) {
+func (suite *UseCaseSuite) ComputeLossBundle(ctx context.Context, sourceTruth string, vb *emissionstypes.ValueBundle, reputer lib.ReputerConfig) (emissionstypes.ValueBundle, error) {
 	if vb == nil {
 		return emissionstypes.ValueBundle{}, errors.New("nil ValueBundle")
 	}
@@ -90,13 +89,15 @@ func (suite *UseCaseSuite) ComputeLossBundle(sourceTruth string, vb *emissionsty
 	}
 
 	lossMethodOptions := reputer.LossFunctionParameters.LossMethodOptions
+	// Height of the nonce the losses are computed for, passed along to the loss function
+	blockHeight := vb.ReputerRequestNonce.GetReputerNonce().GetBlockHeight()
 	// Use the cached IsNeverNegative value
 	is_never_negative := false
 	if reputer.LossFunctionParameters.IsNeverNegative != nil {
 		is_never_negative = *reputer.LossFunctionParameters.IsNeverNegative
 	} else {
 		var err error
-		is_never_negative, err = reputer.LossFunctionEntrypoint.IsLossFunctionNeverNegative(reputer, lossMethodOptions)
+		is_never_negative, err = reputer.LossFunctionEntrypoint.IsLossFunctionNeverNegative(ctx, reputer, blockHeight, lossMethodOptions)
 		if err != nil {
 			return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "failed to determine if loss function is never negative")
 		}
@@ -104,100 +105,155 @@ func (suite *UseCaseSuite) ComputeLossBundle(sourceTruth string, vb *emissionsty
 		reputer.LossFunctionParameters.IsNeverNegative = &is_never_negative
 	}
 
-	losses := emissionstypes.ValueBundle{
-		TopicId:             vb.TopicId,
-		ReputerRequestNonce: vb.ReputerRequestNonce,
-		Reputer:             vb.Reputer,
-		ExtraData:           vb.ExtraData,
+	// All values of the bundle, in bundle order, so their losses can be computed in one batch or concurrently
+	values := []lossValue{
+		{value: vb.CombinedValue, description: "combined value"},
+		{value: vb.NaiveValue, description: "naive value"},
 	}
-
-	computeLoss := func(value alloraMath.Dec, description string) (alloraMath.Dec, error) {
-		lossStr, err := reputer.LossFunctionEntrypoint.LossFunction(reputer, sourceTruth, value.String(), lossMethodOptions)
-		if err != nil {
-			return alloraMath.Dec{}, errorsmod.Wrapf(err, "error computing loss for %s", description)
-		}
-
-		loss, err := alloraMath.NewDecFromString(lossStr)
-		if err != nil {
-			return alloraMath.Dec{}, errorsmod.Wrapf(err, "error parsing loss value for %s", description)
-		}
-
-		if is_never_negative {
-			loss, err = alloraMath.Log10(loss)
-			if err != nil {
-				return alloraMath.Dec{}, errorsmod.Wrapf(err, "error Log10 for %s", description)
-			}
-		}
-
-		if err := emissionstypes.ValidateDec(loss); err != nil {
-			return alloraMath.Dec{}, errorsmod.Wrapf(err, "invalid loss value for %s", description)
-		}
-
-		return loss, nil
+	for i, val := range vb.InfererValues {
+		values = append(values, lossValue{value: val.Value, description: fmt.Sprintf("inferer value %d", i)})
+	}
+	for i, val := range vb.ForecasterValues {
+		values = append(values, lossValue{value: val.Value, description: fmt.Sprintf("forecaster value %d", i)})
+	}
+	for i, val := range vb.OneOutInfererValues {
+		values = append(values, lossValue{value: val.Value, description: fmt.Sprintf("one out inferer value %d", i)})
+	}
+	for i, val := range vb.OneOutForecasterValues {
+		values = append(values, lossValue{value: val.Value, description: fmt.Sprintf("one out forecaster value %d", i)})
+	}
+	for i, val := range vb.OneInForecasterValues {
+		values = append(values, lossValue{value: val.Value, description: fmt.Sprintf("one in forecaster value %d", i)})
 	}
 
-	// Combined Value
-	if combinedLoss, err := computeLoss(vb.CombinedValue, "combined value"); err != nil {
-		return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for combined value")
-	} else {
-		losses.CombinedValue = combinedLoss
+	rawLosses, err := computeRawLosses(ctx, reputer, blockHeight, sourceTruth, values, lossMethodOptions)
+	if err != nil {
+		return emissionstypes.ValueBundle{}, err
 	}
 
-	// Naive Value
-	if naiveLoss, err := computeLoss(vb.NaiveValue, "naive value"); err != nil {
-		return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for naive value")
-	} else {
-		losses.NaiveValue = naiveLoss
+	lossValues := make([]alloraMath.Dec, len(values))
+	lossErrs := make([]error, len(values))
+	for i, lossStr := range rawLosses {
+		lossValues[i], lossErrs[i] = parseLoss(lossStr, values[i].description, is_never_negative)
+	}
+	if err := errors.Join(lossErrs...); err != nil {
+		return emissionstypes.ValueBundle{}, err
 	}
 
-	// Inferer Values
+	// Reassemble the losses in bundle order
+	next := 0
+	nextLoss := func() alloraMath.Dec {
+		next++
+		return lossValues[next-1]
+	}
+	losses := emissionstypes.ValueBundle{
+		TopicId:             vb.TopicId,
+		ReputerRequestNonce: vb.ReputerRequestNonce,
+		Reputer:             vb.Reputer,
+		ExtraData:           vb.ExtraData,
+		CombinedValue:       nextLoss(),
+		NaiveValue:          nextLoss(),
+	}
 	losses.InfererValues = make([]*emissionstypes.WorkerAttributedValue, len(vb.InfererValues))
 	for i, val := range vb.InfererValues {
-		if loss, err := computeLoss(val.Value, fmt.Sprintf("inferer value %d", i)); err != nil {
-			return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for inferer value")
-		} else {
-			losses.InfererValues[i] = &emissionstypes.WorkerAttributedValue{Worker: val.Worker, Value: loss}
-		}
+		losses.InfererValues[i] = &emissionstypes.WorkerAttributedValue{Worker: val.Worker, Value: nextLoss()}
 	}
-
-	// Forecaster Values
 	losses.ForecasterValues = make([]*emissionstypes.WorkerAttributedValue, len(vb.ForecasterValues))
 	for i, val := range vb.ForecasterValues {
-		if loss, err := computeLoss(val.Value, fmt.Sprintf("forecaster value %d", i)); err != nil {
-			return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for forecaster value")
-		} else {
-			losses.ForecasterValues[i] = &emissionstypes.WorkerAttributedValue{Worker: val.Worker, Value: loss}
-		}
+		losses.ForecasterValues[i] = &emissionstypes.WorkerAttributedValue{Worker: val.Worker, Value: nextLoss()}
 	}
-
-	// One Out Inferer Values
 	losses.OneOutInfererValues = make([]*emissionstypes.WithheldWorkerAttributedValue, len(vb.OneOutInfererValues))
 	for i, val := range vb.OneOutInfererValues {
-		if loss, err := computeLoss(val.Value, fmt.Sprintf("one out inferer value %d", i)); err != nil {
-			return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for one-out inferer value")
-		} else {
-			losses.OneOutInfererValues[i] = &emissionstypes.WithheldWorkerAttributedValue{Worker: val.Worker, Value: loss}
-		}
+		losses.OneOutInfererValues[i] = &emissionstypes.WithheldWorkerAttributedValue{Worker: val.Worker, Value: nextLoss()}
 	}
-
-	// One Out Forecaster Values
 	losses.OneOutForecasterValues = make([]*emissionstypes.WithheldWorkerAttributedValue, len(vb.OneOutForecasterValues))
 	for i, val := range vb.OneOutForecasterValues {
-		if loss, err := computeLoss(val.Value, fmt.Sprintf("one out forecaster value %d", i)); err != nil {
-			return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for one-out forecaster value")
-		} else {
-			losses.OneOutForecasterValues[i] = &emissionstypes.WithheldWorkerAttributedValue{Worker: val.Worker, Value: loss}
-		}
+		losses.OneOutForecasterValues[i] = &emissionstypes.WithheldWorkerAttributedValue{Worker: val.Worker, Value: nextLoss()}
 	}
-
-	// One In Forecaster Values
 	losses.OneInForecasterValues = make([]*emissionstypes.WorkerAttributedValue, len(vb.OneInForecasterValues))
 	for i, val := range vb.OneInForecasterValues {
-		if loss, err := computeLoss(val.Value, fmt.Sprintf("one in forecaster value %d", i)); err != nil {
-			return emissionstypes.ValueBundle{}, errorsmod.Wrapf(err, "error computing loss for one-in forecaster value")
-		} else {
-			losses.OneInForecasterValues[i] = &emissionstypes.WorkerAttributedValue{Worker: val.Worker, Value: loss}
+		losses.OneInForecasterValues[i] = &emissionstypes.WorkerAttributedValue{Worker: val.Worker, Value: nextLoss()}
+	}
+	return losses, nil
+}
+
+// Parse the loss returned by the loss function, taking its Log10 if the loss function is never negative
+func parseLoss(lossStr string, description string, isNeverNegative bool) (alloraMath.Dec, error) {
+	loss, err := alloraMath.NewDecFromString(lossStr)
+	if err != nil {
+		return alloraMath.Dec{}, errorsmod.Wrapf(err, "error parsing loss value for %s", description)
+	}
+
+	if isNeverNegative {
+		loss, err = alloraMath.Log10(loss)
+		if err != nil {
+			return alloraMath.Dec{}, errorsmod.Wrapf(err, "error Log10 for %s", description)
+		}
+	}
+
+	if err := emissionstypes.ValidateDec(loss); err != nil {
+		return alloraMath.Dec{}, errorsmod.Wrapf(err, "invalid loss value for %s", description)
+	}
+	return loss, nil
+}
+
+// A value of a bundle whose loss is to be computed, described for errors
+type lossValue struct {
+	value       alloraMath.Dec
+	description string
+}
+
+// Losses of the values, as returned by the loss function, by index. Computed in one call if the loss
+// function adapter supports batches, falling back to concurrent calls per value if it does not.
+// Errors of all values are returned together.
+func computeRawLosses(ctx context.Context, reputer lib.ReputerConfig, blockHeight int64, sourceTruth string, values []lossValue, options map[string]string) ([]string, error) {
+	if batchLossFunction, ok := reputer.LossFunctionEntrypoint.(lib.BatchLossFunction); ok {
+		predictions := make([]string, len(values))
+		for i, v := range values {
+			predictions[i] = v.value.String()
 		}
+		losses, err := batchLossFunction.BatchLossFunction(ctx, reputer, blockHeight, sourceTruth, predictions, options)
+		switch {
+		case err == nil && len(losses) != len(values):
+			return nil, fmt.Errorf("batch loss function returned %d losses for %d values", len(losses), len(values))
+		case err == nil:
+			return losses, nil
+		case !errors.Is(err, lib.ErrBatchLossUnsupported):
+			return nil, errorsmod.Wrapf(err, "error computing batch of losses")
+		}
+		log.Debug().Uint64("topicId", reputer.TopicId).Msg("Batch loss computation unsupported, computing losses one value at a time")
+	}
+
+	// One call per value, at most MaxLossConcurrency at a time, each storing its loss at the index of its value.
+	// Values not started by the time ctx is done fail with its error.
+	losses := make([]string, len(values))
+	errs := make([]error, len(values))
+	slots := make(chan struct{}, reputer.MaxLossConcurrency())
+	var wg sync.WaitGroup
+	for i, v := range values {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			errs[i] = errorsmod.Wrapf(ctx.Err(), "error computing loss for %s", v.description)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, v lossValue) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			loss, err := reputer.LossFunctionEntrypoint.LossFunction(ctx, reputer, blockHeight, sourceTruth, v.value.String(), options)
+			if err != nil {
+				errs[i] = errorsmod.Wrapf(err, "error computing loss for %s", v.description)
+				return
+			}
+			losses[i] = loss
+		}(i, v)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
 	return losses, nil
 }
@@ -2,8 +2,12 @@ package usecase
 
 import (
 	"allora_offchain_node/lib"
+	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	alloraMath "github.com/allora-network/allora-chain/math"
 	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
@@ -11,6 +15,13 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// Height of the reputer nonce the losses are computed for, passed along to the loss function
+const lossNonce = int64(1000)
+
+func reputerRequestNonceAt(blockHeight int64) *emissionstypes.ReputerRequestNonce {
+	return &emissionstypes.ReputerRequestNonce{ReputerNonce: &emissionstypes.Nonce{BlockHeight: blockHeight}}
+}
+
 func TestComputeLossBundle(t *testing.T) {
 	reputerOptions := map[string]string{
 		"method": "sqe",
@@ -41,8 +52,9 @@ func TestComputeLossBundle(t *testing.T) {
 				inferer, _ := alloraMath.NewDecFromString("9.7")
 				forecaster, _ := alloraMath.NewDecFromString("9.8")
 				return &emissionstypes.ValueBundle{
-					CombinedValue: combined,
-					NaiveValue:    naive,
+					ReputerRequestNonce: reputerRequestNonceAt(lossNonce),
+					CombinedValue:       combined,
+					NaiveValue:          naive,
 					InfererValues: []*emissionstypes.WorkerAttributedValue{
 						{Value: inferer},
 					},
@@ -59,11 +71,11 @@ func TestComputeLossBundle(t *testing.T) {
 				"ForecasterValues": "0.04",
 			},
 			mockSetup: func(m *MockAlloraAdapter) {
-				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), "10.0", "9.5", reputerOptions).Return("0.25", nil)
-				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), "10.0", "9.0", reputerOptions).Return("1.00", nil)
-				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), "10.0", "9.7", reputerOptions).Return("0.09", nil)
-				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), "10.0", "9.8", reputerOptions).Return("0.04", nil)
-				// m.On("IsLossFunctionNeverNegative", mock.AnythingOfType("lib.ReputerConfig"), mock.AnythingOfType("string")).Return(true, nil)
+				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10.0", "9.5", reputerOptions).Return("0.25", nil)
+				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10.0", "9.0", reputerOptions).Return("1.00", nil)
+				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10.0", "9.7", reputerOptions).Return("0.09", nil)
+				m.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10.0", "9.8", reputerOptions).Return("0.04", nil)
+				// m.On("IsLossFunctionNeverNegative", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, mock.AnythingOfType("string")).Return(true, nil)
 			},
 			expectError: false,
 		},
@@ -73,12 +85,13 @@ func TestComputeLossBundle(t *testing.T) {
 			valueBundle: func() *emissionstypes.ValueBundle {
 				combined, _ := alloraMath.NewDecFromString("9.5")
 				return &emissionstypes.ValueBundle{
-					CombinedValue: combined,
+					ReputerRequestNonce: reputerRequestNonceAt(lossNonce),
+					CombinedValue:       combined,
 				}
 			}(),
 			reputerConfig: reputerConfig,
 			mockSetup: func(m *MockAlloraAdapter) {
-				m.On("LossFunction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("loss function error"))
+				m.On("LossFunction", mock.Anything, lossNonce, mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("loss function error"))
 			},
 			expectError:   true,
 			errorContains: "error computing loss for combined value",
@@ -89,12 +102,13 @@ func TestComputeLossBundle(t *testing.T) {
 			valueBundle: func() *emissionstypes.ValueBundle {
 				combined, _ := alloraMath.NewDecFromString("9.5")
 				return &emissionstypes.ValueBundle{
-					CombinedValue: combined,
+					ReputerRequestNonce: reputerRequestNonceAt(lossNonce),
+					CombinedValue:       combined,
 				}
 			}(),
 			reputerConfig: reputerConfig,
 			mockSetup: func(m *MockAlloraAdapter) {
-				m.On("LossFunction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("invalid", nil)
+				m.On("LossFunction", mock.Anything, lossNonce, mock.Anything, mock.Anything, mock.Anything).Return("invalid", nil)
 			},
 			expectError:   true,
 			errorContains: "error parsing loss",
@@ -127,7 +141,7 @@ func TestComputeLossBundle(t *testing.T) {
 			tt.reputerConfig.LossFunctionEntrypoint = mockAdapter
 
 			suite := &UseCaseSuite{}
-			result, err := suite.ComputeLossBundle(tt.sourceTruth, tt.valueBundle, tt.reputerConfig)
+			result, err := suite.ComputeLossBundle(context.Background(), tt.sourceTruth, tt.valueBundle, tt.reputerConfig)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -148,3 +162,172 @@ func TestComputeLossBundle(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeLossBundleInBatches(t *testing.T) {
+	reputerOptions := map[string]string{"method": "sqe"}
+	reputerConfig := lib.ReputerConfig{
+		LossFunctionParameters: lib.LossFunctionParameters{
+			LossMethodOptions: reputerOptions,
+			IsNeverNegative:   &[]bool{false}[0],
+		},
+	}
+	valueBundle := &emissionstypes.ValueBundle{
+		ReputerRequestNonce: reputerRequestNonceAt(lossNonce),
+		CombinedValue:       alloraMath.MustNewDecFromString("9.5"),
+		NaiveValue:          alloraMath.MustNewDecFromString("9.0"),
+		InfererValues: []*emissionstypes.WorkerAttributedValue{
+			{Worker: "inferer", Value: alloraMath.MustNewDecFromString("9.7")},
+		},
+		OneInForecasterValues: []*emissionstypes.WorkerAttributedValue{
+			{Worker: "forecaster", Value: alloraMath.MustNewDecFromString("9.8")},
+		},
+	}
+	predictions := []string{"9.5", "9.0", "9.7", "9.8"}
+
+	t.Run("Losses computed in one batch", func(t *testing.T) {
+		mockAdapter := &MockBatchAlloraAdapter{ReturnBasicMockAlloraAdapter()}
+		mockAdapter.On("BatchLossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10.0", predictions, reputerOptions).Return([]string{"0.25", "1.00", "0.09", "0.04"}, nil)
+		reputerConfig.LossFunctionEntrypoint = mockAdapter
+
+		result, err := (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10.0", valueBundle, reputerConfig)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.25", result.CombinedValue.String())
+		assert.Equal(t, "1.00", result.NaiveValue.String())
+		assert.Equal(t, "inferer", result.InfererValues[0].Worker)
+		assert.Equal(t, "0.09", result.InfererValues[0].Value.String())
+		assert.Equal(t, "forecaster", result.OneInForecasterValues[0].Worker)
+		assert.Equal(t, "0.04", result.OneInForecasterValues[0].Value.String())
+		mockAdapter.AssertExpectations(t)
+		mockAdapter.AssertNotCalled(t, "LossFunction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Falls back to one value at a time", func(t *testing.T) {
+		mockAdapter := &MockBatchAlloraAdapter{ReturnBasicMockAlloraAdapter()}
+		mockAdapter.On("BatchLossFunction", mock.Anything, lossNonce, mock.Anything, mock.Anything, mock.Anything).Return(nil, lib.ErrBatchLossUnsupported)
+		for i, loss := range []string{"0.25", "1.00", "0.09", "0.04"} {
+			mockAdapter.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10.0", predictions[i], reputerOptions).Return(loss, nil)
+		}
+		reputerConfig.LossFunctionEntrypoint = mockAdapter
+
+		result, err := (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10.0", valueBundle, reputerConfig)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.25", result.CombinedValue.String())
+		assert.Equal(t, "0.04", result.OneInForecasterValues[0].Value.String())
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Batch with missing losses", func(t *testing.T) {
+		mockAdapter := &MockBatchAlloraAdapter{ReturnBasicMockAlloraAdapter()}
+		mockAdapter.On("BatchLossFunction", mock.Anything, lossNonce, mock.Anything, mock.Anything, mock.Anything).Return([]string{"0.25"}, nil)
+		reputerConfig.LossFunctionEntrypoint = mockAdapter
+
+		_, err := (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10.0", valueBundle, reputerConfig)
+		assert.ErrorContains(t, err, "returned 1 losses for 4 values")
+	})
+}
+
+func TestComputeLossBundleConcurrently(t *testing.T) {
+	reputerOptions := map[string]string{"method": "sqe"}
+	reputerConfig := lib.ReputerConfig{
+		LossConcurrency: 2,
+		LossFunctionParameters: lib.LossFunctionParameters{
+			LossMethodOptions: reputerOptions,
+			IsNeverNegative:   &[]bool{false}[0],
+		},
+	}
+	valueBundle := &emissionstypes.ValueBundle{
+		ReputerRequestNonce: reputerRequestNonceAt(lossNonce),
+		CombinedValue:       alloraMath.MustNewDecFromString("1"),
+		NaiveValue:          alloraMath.MustNewDecFromString("2"),
+	}
+	for _, value := range []string{"3", "4", "5", "6"} {
+		valueBundle.InfererValues = append(valueBundle.InfererValues, &emissionstypes.WorkerAttributedValue{Worker: "inferer" + value, Value: alloraMath.MustNewDecFromString(value)})
+	}
+
+	t.Run("Losses assembled in bundle order with bounded concurrency", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		mockAdapter := ReturnBasicMockAlloraAdapter()
+		for _, value := range []string{"1", "2", "3", "4", "5", "6"} {
+			mockAdapter.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10", value, reputerOptions).Return("0."+value, nil).Run(func(args mock.Arguments) {
+				mu.Lock()
+				inFlight++
+				maxInFlight = max(maxInFlight, inFlight)
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			})
+		}
+		reputerConfig.LossFunctionEntrypoint = mockAdapter
+
+		result, err := (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10", valueBundle, reputerConfig)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.1", result.CombinedValue.String())
+		assert.Equal(t, "0.2", result.NaiveValue.String())
+		for i, inferer := range result.InfererValues {
+			assert.Equal(t, valueBundle.InfererValues[i].Worker, inferer.Worker)
+			assert.Equal(t, "0."+valueBundle.InfererValues[i].Value.String(), inferer.Value.String())
+		}
+		assert.LessOrEqual(t, maxInFlight, 2)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Errors of every value", func(t *testing.T) {
+		mockAdapter := ReturnBasicMockAlloraAdapter()
+		mockAdapter.On("LossFunction", mock.Anything, lossNonce, mock.Anything, "2", mock.Anything).Return("", errors.New("loss function error"))
+		mockAdapter.On("LossFunction", mock.Anything, lossNonce, mock.Anything, "5", mock.Anything).Return("", errors.New("loss function error"))
+		mockAdapter.On("LossFunction", mock.Anything, lossNonce, mock.Anything, "4", mock.Anything).Return("invalid", nil)
+		mockAdapter.On("LossFunction", mock.Anything, lossNonce, mock.Anything, mock.Anything, mock.Anything).Return("0.1", nil)
+		reputerConfig.LossFunctionEntrypoint = mockAdapter
+
+		_, err := (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10", valueBundle, reputerConfig)
+		assert.ErrorContains(t, err, "error computing loss for naive value")
+		assert.ErrorContains(t, err, "error computing loss for inferer value 2")
+
+		valueBundle.NaiveValue = alloraMath.MustNewDecFromString("7")
+		valueBundle.InfererValues[2].Value = alloraMath.MustNewDecFromString("8")
+		_, err = (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10", valueBundle, reputerConfig)
+		assert.ErrorContains(t, err, "error parsing loss value for inferer value 1")
+	})
+
+	t.Run("Values not started once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var calls atomic.Int32
+		mockAdapter := ReturnBasicMockAlloraAdapter()
+		mockAdapter.On("LossFunction", mock.Anything, lossNonce, mock.Anything, mock.Anything, mock.Anything).Return("", context.Canceled).Run(func(args mock.Arguments) {
+			calls.Add(1)
+			// The loss function service is gone and the nonce abandoned
+			cancel()
+		})
+		reputerConfig.LossFunctionEntrypoint = mockAdapter
+
+		_, err := (&UseCaseSuite{}).ComputeLossBundle(ctx, "10", valueBundle, reputerConfig)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorContains(t, err, "error computing loss for inferer value 3")
+		assert.LessOrEqual(t, calls.Load(), int32(2), "at most the calls in flight when cancelled")
+	})
+}
+
+func TestComputeLossBundleAsksWhetherLossesAreNeverNegativeAtNonce(t *testing.T) {
+	reputerOptions := map[string]string{"method": "sqe"}
+	mockAdapter := ReturnBasicMockAlloraAdapter()
+	mockAdapter.On("IsLossFunctionNeverNegative", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, reputerOptions).Return(true, nil).Once()
+	mockAdapter.On("LossFunction", mock.AnythingOfType("lib.ReputerConfig"), lossNonce, "10", "9", reputerOptions).Return("0.5", nil)
+	reputerConfig := lib.ReputerConfig{
+		LossFunctionEntrypoint: mockAdapter,
+		LossFunctionParameters: lib.LossFunctionParameters{LossMethodOptions: reputerOptions},
+	}
+	valueBundle := &emissionstypes.ValueBundle{
+		ReputerRequestNonce: reputerRequestNonceAt(lossNonce),
+		CombinedValue:       alloraMath.MustNewDecFromString("9"),
+		NaiveValue:          alloraMath.MustNewDecFromString("9"),
+	}
+
+	result, err := (&UseCaseSuite{}).ComputeLossBundle(context.Background(), "10", valueBundle, reputerConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "-0.3010299956639811952137388947244930", result.CombinedValue.String(), "log10 of losses that are never negative")
+	mockAdapter.AssertExpectations(t)
+}
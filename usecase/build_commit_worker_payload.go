@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"strings"
 
 	errorsmod "cosmossdk.io/errors"
 	"github.com/rs/zerolog/log"
@@ -15,9 +16,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 )
 
-func (suite *UseCaseSuite) BuildCommitWorkerPayload(worker lib.WorkerConfig, nonce *emissionstypes.Nonce) error {
-	ctx := context.Background()
-
+func (suite *UseCaseSuite) BuildCommitWorkerPayload(ctx context.Context, worker lib.WorkerConfig, nonce *emissionstypes.Nonce) error {
 	if worker.InferenceEntrypoint == nil && worker.ForecastEntrypoint == nil {
 		return errors.New("Worker has no valid Inference or Forecast entrypoints")
 	}
@@ -27,17 +26,23 @@ func (suite *UseCaseSuite) BuildCommitWorkerPayload(worker lib.WorkerConfig, non
 	}
 
 	if worker.InferenceEntrypoint != nil {
-		inference, err := worker.InferenceEntrypoint.CalcInference(worker, nonce.BlockHeight)
+		inference, err := worker.InferenceEntrypoint.CalcInference(ctx, worker, nonce.BlockHeight)
 		if err != nil {
 			return errorsmod.Wrapf(err, "Error computing inference for worker, topicId: %d, blockHeight: %d", worker.TopicId, nonce.BlockHeight)
 		}
-		workerResponse.InfererValue = inference
 		suite.Metrics.IncrementMetricsCounter(lib.InferenceRequestCount, suite.Node.Chain.Address, worker.TopicId)
+		inference, err = suite.GuardInference(ctx, worker, nonce.BlockHeight, inference)
+		if err != nil {
+			if worker.ForecastEntrypoint == nil {
+				return errorsmod.Wrapf(err, "Skipping inference for worker, topicId: %d, blockHeight: %d", worker.TopicId, nonce.BlockHeight)
+			}
+			log.Warn().Err(err).Uint64("topicId", worker.TopicId).Int64("blockHeight", nonce.BlockHeight).Msg("Skipping inference, submitting forecasts only")
+		}
+		workerResponse.InfererValue = inference
 	}
 
 	if worker.ForecastEntrypoint != nil {
-		forecasts := []lib.NodeValue{}
-		forecasts, err := worker.ForecastEntrypoint.CalcForecast(worker, nonce.BlockHeight)
+		forecasts, err := suite.CalcForecasts(ctx, worker, nonce.BlockHeight)
 		if err != nil {
 			return errorsmod.Wrapf(err, "Error computing forecast for worker, topicId: %d, blockHeight: %d", worker.TopicId, nonce.BlockHeight)
 		}
@@ -74,7 +79,7 @@ func (suite *UseCaseSuite) BuildCommitWorkerPayload(worker lib.WorkerConfig, non
 	}
 
 	if suite.Node.Wallet.SubmitTx {
-		_, err = suite.Node.SendDataWithRetry(ctx, req, "Send Worker Data to chain")
+		err = suite.sendPayload(ctx, ActorRoleWorker, worker.TopicId, nonce.BlockHeight, req, "Send Worker Data to chain")
 		if err != nil {
 			return errorsmod.Wrapf(err, "Error sending Worker Data to chain, topicId: %d, blockHeight: %d", worker.TopicId, nonce.BlockHeight)
 		}
@@ -82,9 +87,55 @@ func (suite *UseCaseSuite) BuildCommitWorkerPayload(worker lib.WorkerConfig, non
 	} else {
 		log.Info().Uint64("topicId", worker.TopicId).Msg("SubmitTx=false; Skipping sending Worker Data to chain")
 	}
+	if workerResponse.InfererValue != "" {
+		suite.inferences.submit(worker.TopicId, workerResponse.InfererValue)
+	}
 	return nil
 }
 
+// Forecasts of the worker at the block height. When targeting active inferers, the active inferers of the topic
+// are passed to the forecast adapter and forecasts for other workers are dropped.
+func (suite *UseCaseSuite) CalcForecasts(ctx context.Context, worker lib.WorkerConfig, blockHeight lib.BlockHeight) ([]lib.NodeValue, error) {
+	if !worker.TargetActiveInferers {
+		return worker.ForecastEntrypoint.CalcForecast(ctx, worker, blockHeight)
+	}
+
+	inferers, err := suite.Node.GetActiveInferers(ctx, worker.TopicId)
+	if err != nil {
+		return nil, errorsmod.Wrapf(err, "error getting active inferers")
+	}
+	if len(inferers) == 0 {
+		log.Info().Uint64("topicId", worker.TopicId).Int64("blockHeight", blockHeight).Msg("No active inferers to forecast for")
+		return []lib.NodeValue{}, nil
+	}
+
+	// Copy the parameters, shared with other nonces of the worker
+	parameters := make(map[string]string, len(worker.Parameters)+1)
+	for key, value := range worker.Parameters {
+		parameters[key] = value
+	}
+	parameters[lib.ActiveInferersParameter] = strings.Join(inferers, ",")
+	worker.Parameters = parameters
+
+	forecasts, err := worker.ForecastEntrypoint.CalcForecast(ctx, worker, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool, len(inferers))
+	for _, inferer := range inferers {
+		active[inferer] = true
+	}
+	targeted := []lib.NodeValue{}
+	for _, forecast := range forecasts {
+		if !active[forecast.Worker] {
+			log.Warn().Uint64("topicId", worker.TopicId).Int64("blockHeight", blockHeight).Str("worker", forecast.Worker).Msg("Dropping forecast for worker that is not an active inferer")
+			continue
+		}
+		targeted = append(targeted, forecast)
+	}
+	return targeted, nil
+}
+
 func (suite *UseCaseSuite) BuildWorkerPayload(workerResponse lib.WorkerResponse, nonce emissionstypes.BlockHeight) (emissionstypes.InferenceForecastBundle, error) {
 
 	inferenceForecastsBundle := emissionstypes.InferenceForecastBundle{}
@@ -2,11 +2,15 @@ package usecase
 
 import (
 	"allora_offchain_node/lib"
+	"context"
 	"testing"
 
 	alloraMath "github.com/allora-network/allora-chain/math"
 	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 func (suite *UseCaseSuite) SetupTest() {
@@ -158,3 +162,46 @@ func TestComputeWorkerBundle(t *testing.T) {
 }
 
 // Add more test functions as needed
+
+// Query client answering with the active inferers of every topic
+type activeInferersQueryClient struct {
+	emissionstypes.QueryServiceClient
+	inferers []string
+}
+
+func (c *activeInferersQueryClient) GetActiveInferersForTopic(ctx context.Context, in *emissionstypes.GetActiveInferersForTopicRequest, opts ...grpc.CallOption) (*emissionstypes.GetActiveInferersForTopicResponse, error) {
+	return &emissionstypes.GetActiveInferersForTopicResponse{Inferers: c.inferers}, nil
+}
+
+func TestCalcForecastsTargetsActiveInferers(t *testing.T) {
+	suite := &UseCaseSuite{}
+	suite.Node.Chain.EmissionsQueryClient = &activeInferersQueryClient{inferers: []string{"allo1inferer1", "allo1inferer2"}}
+	worker := lib.WorkerConfig{TopicId: 1, Parameters: map[string]string{"Token": "ETH"}}
+	forecasts := []lib.NodeValue{{Worker: "allo1inferer1", Value: "0.1"}, {Worker: "allo1retired", Value: "0.2"}, {Worker: "allo1inferer2", Value: "0.3"}}
+
+	mockAdapter := NewMockAlloraAdapter()
+	worker.ForecastEntrypoint = mockAdapter
+	mockAdapter.On("CalcForecast", worker, int64(10)).Return(forecasts, nil).Once()
+	targeted := worker
+	targeted.TargetActiveInferers = true
+	mockAdapter.On("CalcForecast", mock.MatchedBy(func(config lib.WorkerConfig) bool {
+		return config.Parameters[lib.ActiveInferersParameter] == "allo1inferer1,allo1inferer2" && config.Parameters["Token"] == "ETH"
+	}), int64(10)).Return(forecasts, nil).Once()
+
+	// Untargeted workers are not told the inferers and keep all forecasts
+	all, err := suite.CalcForecasts(context.Background(), worker, 10)
+	require.NoError(t, err)
+	assert.Equal(t, forecasts, all)
+
+	active, err := suite.CalcForecasts(context.Background(), targeted, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []lib.NodeValue{{Worker: "allo1inferer1", Value: "0.1"}, {Worker: "allo1inferer2", Value: "0.3"}}, active)
+	assert.NotContains(t, targeted.Parameters, lib.ActiveInferersParameter, "the parameters of the worker are left untouched")
+	mockAdapter.AssertExpectations(t)
+
+	// Without active inferers, there is nothing to forecast
+	suite.Node.Chain.EmissionsQueryClient = &activeInferersQueryClient{}
+	none, err := suite.CalcForecasts(context.Background(), targeted, 10)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
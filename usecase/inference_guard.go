@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Inferences of the workers, by topic, for the checks against previous inferences.
+// Kept in memory, so the checks start over when the node restarts.
+type inferenceHistory struct {
+	mu     sync.Mutex
+	topics map[emissionstypes.TopicId]*topicInferences
+}
+
+type topicInferences struct {
+	submitted *alloraMath.Dec // previous inference submitted
+	sourced   string          // previous inference returned by the source
+	repeats   int64           // times in a row the source returned it
+}
+
+func (h *inferenceHistory) topic(topicId emissionstypes.TopicId) *topicInferences {
+	if h.topics == nil {
+		h.topics = map[emissionstypes.TopicId]*topicInferences{}
+	}
+	topic, ok := h.topics[topicId]
+	if !ok {
+		topic = &topicInferences{}
+		h.topics[topicId] = topic
+	}
+	return topic
+}
+
+// Previous inference submitted for the topic, nil if none
+func (h *inferenceHistory) submitted(topicId emissionstypes.TopicId) *alloraMath.Dec {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.topic(topicId).submitted
+}
+
+// Record the inference returned by the source of the topic, returning the times in a row it was returned
+func (h *inferenceHistory) sourced(topicId emissionstypes.TopicId, inference string) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	topic := h.topic(topicId)
+	if topic.repeats > 0 && sameInference(topic.sourced, inference) {
+		topic.repeats++
+	} else {
+		topic.sourced, topic.repeats = inference, 1
+	}
+	return topic.repeats
+}
+
+// Record the inference submitted for the topic
+func (h *inferenceHistory) submit(topicId emissionstypes.TopicId, inference string) {
+	value, err := alloraMath.NewDecFromString(inference)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topic(topicId).submitted = &value
+}
+
+// Whether the inferences are the same number, or the same string if either is not a number
+func sameInference(a string, b string) bool {
+	x, errX := alloraMath.NewDecFromString(a)
+	y, errY := alloraMath.NewDecFromString(b)
+	if errX != nil || errY != nil || !x.IsFinite() || !y.IsFinite() {
+		return a == b
+	}
+	return x.Equal(y)
+}
+
+// Outcome of checking an inference against the guards of its worker
+type inferenceCheck struct {
+	violations []string       // why the inference violates the guards, none if it does not
+	clamped    alloraMath.Dec // the inference clamped within the bounds and relative change
+	clampable  bool           // whether clamping fixes the violations
+}
+
+// Check the inference against the bounds and the relative change to the previous inference submitted, if any.
+// Inferences that are not finite numbers cannot be clamped.
+func checkInference(guards lib.InferenceGuardConfig, inference string, previous *alloraMath.Dec) inferenceCheck {
+	value, err := alloraMath.NewDecFromString(inference)
+	if err != nil && !errors.Is(err, alloraMath.ErrInfiniteString) {
+		return inferenceCheck{violations: []string{"not a decimal"}}
+	}
+	if err != nil || !value.IsFinite() {
+		return inferenceCheck{violations: []string{"not a finite number"}}
+	}
+	check := inferenceCheck{clamped: value, clampable: true}
+	fail := func(err error) inferenceCheck {
+		return inferenceCheck{violations: append(check.violations, err.Error())}
+	}
+
+	if previous != nil && !previous.IsZero() && guards.MaxRelativeChange > 0 {
+		maxChange, err := alloraMath.NewDecFromString(fmt.Sprintf("%g", guards.MaxRelativeChange))
+		if err != nil {
+			return fail(err)
+		}
+		delta, err := value.Sub(*previous)
+		if err != nil {
+			return fail(err)
+		}
+		absDelta, err := delta.Abs()
+		if err != nil {
+			return fail(err)
+		}
+		absPrevious, err := previous.Abs()
+		if err != nil {
+			return fail(err)
+		}
+		allowed, err := absPrevious.Mul(maxChange)
+		if err != nil {
+			return fail(err)
+		}
+		if absDelta.Gt(allowed) {
+			check.violations = append(check.violations, fmt.Sprintf("changed by more than %g relative to the previous inference %s", guards.MaxRelativeChange, previous))
+			if delta.IsNegative() {
+				check.clamped, err = previous.Sub(allowed)
+			} else {
+				check.clamped, err = previous.Add(allowed)
+			}
+			if err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	min, max, err := guards.Bounds()
+	if err != nil {
+		return fail(err)
+	}
+	// The bounds win over the relative change, should they disagree
+	if min != nil {
+		if value.Lt(*min) {
+			check.violations = append(check.violations, fmt.Sprintf("below the minimum %s", min))
+		}
+		if check.clamped.Lt(*min) {
+			check.clamped = *min
+		}
+	}
+	if max != nil {
+		if value.Gt(*max) {
+			check.violations = append(check.violations, fmt.Sprintf("above the maximum %s", max))
+		}
+		if check.clamped.Gt(*max) {
+			check.clamped = *max
+		}
+	}
+	return check
+}
+
+// Inference to submit in place of the one returned by the source of the worker, following the policy of its guards
+// when it violates them. Returns an error when no inference should be submitted.
+func (suite *UseCaseSuite) GuardInference(ctx context.Context, worker lib.WorkerConfig, blockHeight lib.BlockHeight, inference string) (string, error) {
+	guards := worker.InferenceGuards
+	previous := suite.inferences.submitted(worker.TopicId)
+	check := checkInference(guards, inference, previous)
+	if repeats := suite.inferences.sourced(worker.TopicId, inference); guards.StaleRepeats > 0 && repeats >= guards.StaleRepeats {
+		check.violations = append(check.violations, fmt.Sprintf("stale, returned %d times in a row", repeats))
+		check.clampable = false
+	}
+	if len(check.violations) == 0 {
+		return inference, nil
+	}
+
+	violation := strings.Join(check.violations, ", ")
+	logger := log.Warn().Uint64("topicId", worker.TopicId).Int64("blockHeight", blockHeight).Str("inference", inference).Str("violation", violation)
+	switch guards.GuardPolicy() {
+	case lib.InferenceGuardPolicyClamp:
+		if check.clampable {
+			logger.Str("clamped", check.clamped.String()).Msg("Clamping inference violating the guards of the worker")
+			return check.clamped.String(), nil
+		}
+	case lib.InferenceGuardPolicyFallback:
+		fallback, err := suite.fallbackInference(ctx, worker, blockHeight, previous)
+		if err == nil {
+			logger.Str("fallback", fallback).Msg("Falling back on the fallback inference of the worker")
+			return fallback, nil
+		}
+		violation = fmt.Sprintf("%s, and the fallback failed: %v", violation, err)
+	}
+	return "", fmt.Errorf("inference %q violates the guards of the worker: %s", inference, violation)
+}
+
+// Inference of the fallback entrypoint of the worker, checked against the guards but for staleness
+func (suite *UseCaseSuite) fallbackInference(ctx context.Context, worker lib.WorkerConfig, blockHeight lib.BlockHeight, previous *alloraMath.Dec) (string, error) {
+	guards := worker.InferenceGuards
+	if guards.FallbackEntrypoint == nil {
+		return "", errors.New("no fallback inference entrypoint")
+	}
+	worker.Parameters = guards.FallbackEntrypointParameters(worker)
+	inference, err := guards.FallbackEntrypoint.CalcInference(ctx, worker, blockHeight)
+	if err != nil {
+		return "", err
+	}
+	if check := checkInference(guards, inference, previous); len(check.violations) > 0 {
+		return "", fmt.Errorf("fallback inference %q: %s", inference, strings.Join(check.violations, ", "))
+	}
+	return inference, nil
+}
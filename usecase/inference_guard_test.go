@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"errors"
+	"testing"
+
+	alloraMath "github.com/allora-network/allora-chain/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckInference(t *testing.T) {
+	guards := lib.InferenceGuardConfig{MinValue: "0", MaxValue: "5000", MaxRelativeChange: 0.1}
+	previous := alloraMath.MustNewDecFromString("3000")
+	tests := []struct {
+		name       string
+		inference  string
+		previous   *alloraMath.Dec
+		violations int
+		clamped    string
+		clampable  bool
+	}{
+		{name: "within the guards", inference: "3100", previous: &previous, clamped: "3100", clampable: true},
+		{name: "no previous inference", inference: "4900", clamped: "4900", clampable: true},
+		{name: "below the minimum", inference: "-1", violations: 1, clamped: "0", clampable: true},
+		{name: "above the maximum", inference: "7000", violations: 1, clamped: "5000", clampable: true},
+		{name: "changed too much", inference: "2000", previous: &previous, violations: 1, clamped: "2700.0", clampable: true},
+		{name: "changed too much and out of bounds", inference: "9000", previous: &previous, violations: 2, clamped: "3300.0", clampable: true},
+		{name: "NaN", inference: "NaN", violations: 1},
+		{name: "infinite", inference: "-Inf", violations: 1},
+		{name: "not a decimal", inference: "high", violations: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			check := checkInference(guards, test.inference, test.previous)
+			assert.Len(t, check.violations, test.violations, check.violations)
+			assert.Equal(t, test.clampable, check.clampable)
+			if test.clampable {
+				assert.Equal(t, test.clamped, check.clamped.String())
+			}
+		})
+	}
+}
+
+func TestGuardInferenceFollowsPolicy(t *testing.T) {
+	ctx := context.Background()
+	worker := lib.WorkerConfig{TopicId: 1, Parameters: map[string]string{"Token": "ETH"}, InferenceGuards: lib.InferenceGuardConfig{
+		MinValue:          "0",
+		MaxValue:          "5000",
+		MaxRelativeChange: 0.5,
+		StaleRepeats:      3,
+	}}
+
+	t.Run("skip", func(t *testing.T) {
+		suite := &UseCaseSuite{}
+		inference, err := suite.GuardInference(ctx, worker, 10, "3000")
+		require.NoError(t, err)
+		assert.Equal(t, "3000", inference)
+		suite.inferences.submit(worker.TopicId, inference)
+
+		_, err = suite.GuardInference(ctx, worker, 11, "NaN")
+		assert.ErrorContains(t, err, "not a finite number")
+		_, err = suite.GuardInference(ctx, worker, 12, "6000")
+		assert.ErrorContains(t, err, "above the maximum 5000")
+
+		// The same value repeated is stale once returned StaleRepeats times in a row
+		_, err = suite.GuardInference(ctx, worker, 13, "3000")
+		require.NoError(t, err)
+		_, err = suite.GuardInference(ctx, worker, 14, "3000.00")
+		require.NoError(t, err)
+		_, err = suite.GuardInference(ctx, worker, 15, "3e3")
+		assert.ErrorContains(t, err, "stale, returned 3 times in a row")
+	})
+
+	t.Run("clamp", func(t *testing.T) {
+		suite := &UseCaseSuite{}
+		clamping := worker
+		clamping.InferenceGuards.Policy = lib.InferenceGuardPolicyClamp
+		suite.inferences.submit(worker.TopicId, "1000")
+
+		inference, err := suite.GuardInference(ctx, clamping, 10, "4000")
+		require.NoError(t, err)
+		assert.Equal(t, "1500.0", inference, "clamped to the relative change")
+		inference, err = suite.GuardInference(ctx, clamping, 11, "-10")
+		require.NoError(t, err)
+		assert.Equal(t, "500.0", inference)
+
+		// Non-finite inferences cannot be clamped
+		_, err = suite.GuardInference(ctx, clamping, 12, "Infinity")
+		assert.ErrorContains(t, err, "not a finite number")
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		suite := &UseCaseSuite{}
+		fallbackAdapter := NewMockAlloraAdapter()
+		fallingBack := worker
+		fallingBack.InferenceGuards.Policy = lib.InferenceGuardPolicyFallback
+		fallingBack.InferenceGuards.FallbackEntrypoint = fallbackAdapter
+		fallingBack.InferenceGuards.FallbackParameters = map[string]string{"Token": "ETH", "InferenceEndpoint": "http://fallback"}
+		fallbackWorker := fallingBack
+		fallbackWorker.Parameters = fallingBack.InferenceGuards.FallbackParameters
+		fallbackAdapter.On("CalcInference", fallbackWorker, int64(10)).Return("3050", nil).Once()
+		fallbackAdapter.On("CalcInference", fallbackWorker, int64(11)).Return("9000", nil).Once()
+		fallbackAdapter.On("CalcInference", fallbackWorker, int64(12)).Return("", errors.New("fallback down")).Once()
+
+		inference, err := suite.GuardInference(ctx, fallingBack, 10, "-3000")
+		require.NoError(t, err)
+		assert.Equal(t, "3050", inference)
+
+		// Fallback inferences violating the guards are not submitted either
+		_, err = suite.GuardInference(ctx, fallingBack, 11, "-3000")
+		assert.ErrorContains(t, err, `fallback inference "9000": above the maximum 5000`)
+		_, err = suite.GuardInference(ctx, fallingBack, 12, "-3000")
+		assert.ErrorContains(t, err, "fallback failed: fallback down")
+		fallbackAdapter.AssertExpectations(t)
+	})
+
+	t.Run("unguarded", func(t *testing.T) {
+		suite := &UseCaseSuite{}
+		unguarded := lib.WorkerConfig{TopicId: 1}
+		for i := 0; i < 5; i++ {
+			inference, err := suite.GuardInference(ctx, unguarded, int64(10+i), "-123456789")
+			require.NoError(t, err)
+			assert.Equal(t, "-123456789", inference)
+		}
+		_, err := suite.GuardInference(ctx, unguarded, 15, "NaN")
+		assert.ErrorContains(t, err, "not a finite number")
+	})
+}
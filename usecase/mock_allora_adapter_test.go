@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"allora_offchain_node/lib"
+	"context"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -15,24 +16,24 @@ func (m *MockAlloraAdapter) Name() string {
 	return args.String(0)
 }
 
-func (m *MockAlloraAdapter) CalcInference(config lib.WorkerConfig, timestamp int64) (string, error) {
+func (m *MockAlloraAdapter) CalcInference(ctx context.Context, config lib.WorkerConfig, timestamp int64) (string, error) {
 	args := m.Called(config, timestamp)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAlloraAdapter) CalcForecast(config lib.WorkerConfig, timestamp int64) ([]lib.NodeValue, error) {
+func (m *MockAlloraAdapter) CalcForecast(ctx context.Context, config lib.WorkerConfig, timestamp int64) ([]lib.NodeValue, error) {
 	args := m.Called(config, timestamp)
 	return args.Get(0).([]lib.NodeValue), args.Error(1)
 }
 
-func (m *MockAlloraAdapter) GroundTruth(config lib.ReputerConfig, timestamp int64) (lib.Truth, error) {
+func (m *MockAlloraAdapter) GroundTruth(ctx context.Context, config lib.ReputerConfig, timestamp int64) (lib.Truth, error) {
 	args := m.Called(config, timestamp)
 	return args.Get(0).(lib.Truth), args.Error(1)
 }
 
 // Update LossFunction to match the new signature
-func (m *MockAlloraAdapter) LossFunction(node lib.ReputerConfig, sourceTruth string, inferenceValue string, options map[string]string) (string, error) {
-	args := m.Called(node, sourceTruth, inferenceValue, options)
+func (m *MockAlloraAdapter) LossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, sourceTruth string, inferenceValue string, options map[string]string) (string, error) {
+	args := m.Called(node, blockHeight, sourceTruth, inferenceValue, options)
 	return args.String(0), args.Error(1)
 }
 
@@ -52,11 +53,22 @@ func (m *MockAlloraAdapter) CanSourceGroundTruthAndComputeLoss() bool {
 }
 
 // Add the new IsLossFunctionNeverNegative method
-func (m *MockAlloraAdapter) IsLossFunctionNeverNegative(node lib.ReputerConfig, options map[string]string) (bool, error) {
-	args := m.Called(node, options)
+func (m *MockAlloraAdapter) IsLossFunctionNeverNegative(ctx context.Context, node lib.ReputerConfig, blockHeight int64, options map[string]string) (bool, error) {
+	args := m.Called(node, blockHeight, options)
 	return args.Bool(0), args.Error(1)
 }
 
+// Mock adapter that also computes losses in batches
+type MockBatchAlloraAdapter struct {
+	*MockAlloraAdapter
+}
+
+func (m *MockBatchAlloraAdapter) BatchLossFunction(ctx context.Context, node lib.ReputerConfig, blockHeight int64, sourceTruth string, inferenceValues []string, options map[string]string) ([]string, error) {
+	args := m.Called(node, blockHeight, sourceTruth, inferenceValues, options)
+	losses, _ := args.Get(0).([]string)
+	return losses, args.Error(1)
+}
+
 func NewMockAlloraAdapter() *MockAlloraAdapter {
 	m := &MockAlloraAdapter{}
 
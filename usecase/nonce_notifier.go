@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"sync"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Wakes worker and reputer processes when a nonce may have opened on their topic, based on new blocks
+// received over the node's websocket. While the subscription is down, processes poll every LoopSeconds instead.
+type NonceNotifier struct {
+	// Websocket endpoint to subscribe to, that of the rpc node queries currently go to unless configured explicitly
+	websocketUrl   func() (string, error)
+	reconnectDelay time.Duration
+	// Height at which the next worker nonce of a topic is expected to open
+	nextWorkerNonceHeight func(ctx context.Context, topicId emissionstypes.TopicId) (lib.BlockHeight, error)
+
+	mu              sync.Mutex
+	subscribed      bool
+	wakers          map[actorId]chan struct{}
+	nextWorkerNonce map[emissionstypes.TopicId]lib.BlockHeight
+	wakeAt          map[actorId]lib.BlockHeight // height at which to wake a process, as asked for with WakeAt
+	lookingUp       map[emissionstypes.TopicId]bool
+	lookupAt        map[emissionstypes.TopicId]lib.BlockHeight // height before which not to look up the next worker nonce again
+	lookupBackoff   map[emissionstypes.TopicId]lib.BlockHeight // blocks until the next lookup, doubled by lookups that failed or found the epoch late
+}
+
+func NewNonceNotifier(node *lib.NodeConfig) (*NonceNotifier, error) {
+	websocketUrl := func() (string, error) {
+		nodeRpc := node.Wallet.NodeRpc
+		if node.Chain.Rpc != nil && node.Chain.Rpc.Active() != nil {
+			nodeRpc = node.Chain.Rpc.Active().Url
+		}
+		return node.Subscription.WebsocketEndpoint(nodeRpc)
+	}
+	if _, err := websocketUrl(); err != nil {
+		return nil, err
+	}
+	return &NonceNotifier{
+		websocketUrl:          websocketUrl,
+		reconnectDelay:        node.Subscription.ReconnectDelay(),
+		nextWorkerNonceHeight: node.GetNextWorkerNonceHeight,
+		wakers:                make(map[actorId]chan struct{}),
+		nextWorkerNonce:       make(map[emissionstypes.TopicId]lib.BlockHeight),
+		wakeAt:                make(map[actorId]lib.BlockHeight),
+		lookingUp:             make(map[emissionstypes.TopicId]bool),
+		lookupAt:              make(map[emissionstypes.TopicId]lib.BlockHeight),
+		lookupBackoff:         make(map[emissionstypes.TopicId]lib.BlockHeight),
+	}, nil
+}
+
+// Keep the new block subscription up until ctx is done, resubscribing after the reconnect delay whenever it drops,
+// to the rpc node active at the time
+func (n *NonceNotifier) Run(ctx context.Context) {
+	for {
+		websocketUrl, err := n.websocketUrl()
+		var blocks <-chan lib.NewBlockEvent
+		if err == nil {
+			blocks, err = lib.SubscribeNewBlocks(ctx, websocketUrl)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("url", websocketUrl).Msg("Could not subscribe to new blocks, polling for nonces")
+		} else {
+			log.Info().Str("url", websocketUrl).Msg("Subscribed to new blocks")
+			// Nonces may have opened while not subscribed, so have every process look once
+			n.setSubscribed(true)
+			for block := range blocks {
+				n.handleNewBlock(ctx, block)
+			}
+			n.setSubscribed(false)
+		}
+
+		timer := time.NewTimer(n.reconnectDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Wait until the process should look for new nonces on its topic: when woken by a new block while subscribed,
+// or after the given number of seconds while not. Returns false if ctx is done, signalling the caller to stop.
+func (n *NonceNotifier) Wait(ctx context.Context, role ActorRole, topicId emissionstypes.TopicId, seconds int64) bool {
+	n.mu.Lock()
+	waker := n.waker(role, topicId)
+	subscribed := n.subscribed
+	n.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if !subscribed {
+		timer := time.NewTimer(time.Duration(seconds) * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-waker:
+		return true
+	case <-timeout:
+		return true
+	}
+}
+
+// Wake the process once a block at the height is received, e.g. when the submission window of a nonce opens.
+// The lowest height asked for is kept until reached.
+func (n *NonceNotifier) WakeAt(role ActorRole, topicId emissionstypes.TopicId, height lib.BlockHeight) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	id := actorId{role: role, topicId: topicId}
+	if current, ok := n.wakeAt[id]; !ok || height < current {
+		n.wakeAt[id] = height
+	}
+}
+
+type actorId struct {
+	role    ActorRole
+	topicId emissionstypes.TopicId
+}
+
+// Get or create the wake channel of a process. Must be called with the lock held
+func (n *NonceNotifier) waker(role ActorRole, topicId emissionstypes.TopicId) chan struct{} {
+	id := actorId{role: role, topicId: topicId}
+	waker, ok := n.wakers[id]
+	if !ok {
+		waker = make(chan struct{}, 1)
+		n.wakers[id] = waker
+	}
+	return waker
+}
+
+// Wake a process without blocking. A process already due to wake up stays so. Must be called with the lock held
+func (n *NonceNotifier) wake(role ActorRole, topicId emissionstypes.TopicId) {
+	select {
+	case n.waker(role, topicId) <- struct{}{}:
+	default:
+	}
+}
+
+// Switch between subscribed and polling mode, waking every process so it picks up the change
+func (n *NonceNotifier) setSubscribed(subscribed bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subscribed = subscribed
+	for _, waker := range n.wakers {
+		select {
+		case waker <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Wake the reputers of the topics whose worker nonce closed in the block, which adds an unfulfilled reputer nonce
+// whose submission window only opens GroundTruthLag blocks later, the processes that asked to be woken by the block,
+// and the workers of the topics whose epoch ended by the block
+func (n *NonceNotifier) handleNewBlock(ctx context.Context, block lib.NewBlockEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, topicId := range block.TopicIds(lib.EVENT_WORKER_LAST_COMMIT_SET) {
+		log.Debug().Uint64("topicId", topicId).Int64("height", block.Height).Msg("Reputer nonce added, waking reputer")
+		n.wake(ActorRoleReputer, topicId)
+	}
+	for id, height := range n.wakeAt {
+		if block.Height >= height {
+			log.Debug().Str("role", string(id.role)).Uint64("topicId", id.topicId).Int64("height", block.Height).Msg("Height reached, waking process")
+			n.wake(id.role, id.topicId)
+			delete(n.wakeAt, id)
+		}
+	}
+
+	for id := range n.wakers {
+		if id.role != ActorRoleWorker {
+			continue
+		}
+		if next, known := n.nextWorkerNonce[id.topicId]; known {
+			if block.Height < next {
+				continue
+			}
+			log.Debug().Uint64("topicId", id.topicId).Int64("height", block.Height).Msg("Worker epoch ended, waking worker")
+			n.wake(ActorRoleWorker, id.topicId)
+			delete(n.nextWorkerNonce, id.topicId)
+		}
+		if n.lookingUp[id.topicId] || block.Height < n.lookupAt[id.topicId] {
+			continue
+		}
+		// Looked up off the websocket read path, so a slow node does not hold up the blocks that follow
+		n.lookingUp[id.topicId] = true
+		go n.lookUpNextWorkerNonce(ctx, id.topicId, block.Height)
+	}
+}
+
+// Look up when the next epoch of the topic ends. Lookups that fail or find the epoch not ended on time
+// are made again after a number of blocks that doubles with every such lookup in a row, up to a limit.
+func (n *NonceNotifier) lookUpNextWorkerNonce(ctx context.Context, topicId emissionstypes.TopicId, height lib.BlockHeight) {
+	next, err := n.nextWorkerNonceHeight(ctx, topicId)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.lookingUp, topicId)
+	if err == nil && next > height {
+		n.nextWorkerNonce[topicId] = next
+		delete(n.lookupBackoff, topicId)
+		return
+	}
+
+	backoff := max(n.lookupBackoff[topicId], 1)
+	n.lookupBackoff[topicId] = min(2*backoff, lib.MAX_NONCE_LOOKUP_BACKOFF_BLOCKS)
+	if err != nil {
+		log.Warn().Err(err).Uint64("topicId", topicId).Int64("retryHeight", height+backoff).Msg("Could not get next worker nonce height of topic")
+		n.lookupAt[topicId] = height + backoff
+		return
+	}
+	// The epoch of the topic is late, so wake the worker again in case it has ended by then
+	n.nextWorkerNonce[topicId] = height + backoff
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Stand-in for the CometBFT websocket: acknowledges the subscription, then forwards the given messages
+func newTestWebsocketServer(t *testing.T, messages <-chan interface{}, disconnect <-chan struct{}) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		var subscribe map[string]interface{}
+		if !assert.NoError(t, conn.ReadJSON(&subscribe)) {
+			return
+		}
+		assert.Equal(t, "subscribe", subscribe["method"])
+		assert.NoError(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": map[string]interface{}{}}))
+
+		for {
+			select {
+			case message := <-messages:
+				assert.NoError(t, conn.WriteJSON(message))
+			case <-disconnect:
+				return
+			}
+		}
+	}))
+}
+
+func newBlockMessage(height int64, events map[string][]string) interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"result": map[string]interface{}{
+			"query": lib.NEW_BLOCK_SUBSCRIPTION_QUERY,
+			"data": map[string]interface{}{
+				"type": "tendermint/event/NewBlock",
+				"value": map[string]interface{}{
+					"block": map[string]interface{}{"header": map[string]interface{}{"height": strconv.FormatInt(height, 10)}},
+				},
+			},
+			"events": events,
+		},
+	}
+}
+
+func (n *NonceNotifier) isSubscribed() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.subscribed
+}
+
+func newTestNonceNotifier(websocketUrl string, nextWorkerNonceHeight func(ctx context.Context, topicId emissionstypes.TopicId) (lib.BlockHeight, error)) *NonceNotifier {
+	return &NonceNotifier{
+		websocketUrl:          func() (string, error) { return websocketUrl, nil },
+		reconnectDelay:        time.Hour,
+		nextWorkerNonceHeight: nextWorkerNonceHeight,
+		wakers:                make(map[actorId]chan struct{}),
+		nextWorkerNonce:       make(map[emissionstypes.TopicId]lib.BlockHeight),
+		wakeAt:                make(map[actorId]lib.BlockHeight),
+		lookingUp:             make(map[emissionstypes.TopicId]bool),
+		lookupAt:              make(map[emissionstypes.TopicId]lib.BlockHeight),
+		lookupBackoff:         make(map[emissionstypes.TopicId]lib.BlockHeight),
+	}
+}
+
+func (n *NonceNotifier) isLookingUp(topicId emissionstypes.TopicId) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lookingUp[topicId]
+}
+
+// True if the process is woken within the timeout
+func wokenWithin(n *NonceNotifier, role ActorRole, topicId emissionstypes.TopicId, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return n.Wait(ctx, role, topicId, 3600)
+}
+
+func TestNonceNotifierWakesActorsOnNewBlocks(t *testing.T) {
+	messages := make(chan interface{})
+	disconnect := make(chan struct{})
+	server := newTestWebsocketServer(t, messages, disconnect)
+	defer server.Close()
+
+	notifier := newTestNonceNotifier("ws"+strings.TrimPrefix(server.URL, "http"), func(ctx context.Context, topicId emissionstypes.TopicId) (lib.BlockHeight, error) {
+		return 102, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Processes registered before subscribing are woken once the subscription is up
+	notifier.mu.Lock()
+	notifier.waker(ActorRoleWorker, 1)
+	notifier.waker(ActorRoleReputer, 2)
+	notifier.mu.Unlock()
+	go notifier.Run(ctx)
+	assert.Eventually(t, notifier.isSubscribed, time.Second, 10*time.Millisecond)
+	assert.True(t, wokenWithin(notifier, ActorRoleWorker, 1, time.Second))
+	assert.True(t, wokenWithin(notifier, ActorRoleReputer, 2, time.Second))
+
+	// Reputer nonce added on topic 2, worker epoch of topic 1 ends at 102
+	messages <- newBlockMessage(100, map[string][]string{
+		"tm.event": {"NewBlock"},
+		lib.EVENT_WORKER_LAST_COMMIT_SET + ".topic_id": {`"2"`},
+	})
+	assert.True(t, wokenWithin(notifier, ActorRoleReputer, 2, time.Second))
+	assert.False(t, wokenWithin(notifier, ActorRoleWorker, 1, 50*time.Millisecond))
+
+	messages <- newBlockMessage(101, map[string][]string{"tm.event": {"NewBlock"}})
+	assert.False(t, wokenWithin(notifier, ActorRoleWorker, 1, 50*time.Millisecond))
+
+	messages <- newBlockMessage(102, map[string][]string{"tm.event": {"NewBlock"}})
+	assert.True(t, wokenWithin(notifier, ActorRoleWorker, 1, time.Second))
+	assert.False(t, wokenWithin(notifier, ActorRoleReputer, 2, 50*time.Millisecond))
+
+	// Dropped subscription falls back to polling every LoopSeconds
+	close(disconnect)
+	assert.Eventually(t, func() bool { return !notifier.isSubscribed() }, time.Second, 10*time.Millisecond)
+	assert.True(t, notifier.Wait(ctx, ActorRoleReputer, 2, 0))
+	assert.True(t, notifier.Wait(ctx, ActorRoleReputer, 2, 0))
+}
+
+func TestNonceNotifierWakesAtRequestedHeight(t *testing.T) {
+	notifier := newTestNonceNotifier("", nil)
+	ctx := context.Background()
+	notifier.WakeAt(ActorRoleReputer, 2, 160)
+	notifier.WakeAt(ActorRoleReputer, 2, 170)
+
+	notifier.handleNewBlock(ctx, lib.NewBlockEvent{Height: 159})
+	assert.False(t, wokenWithin(notifier, ActorRoleReputer, 2, 50*time.Millisecond))
+
+	notifier.handleNewBlock(ctx, lib.NewBlockEvent{Height: 160})
+	assert.True(t, wokenWithin(notifier, ActorRoleReputer, 2, time.Second))
+
+	notifier.handleNewBlock(ctx, lib.NewBlockEvent{Height: 170})
+	assert.False(t, wokenWithin(notifier, ActorRoleReputer, 2, 50*time.Millisecond), "woken once")
+}
+
+func TestNonceNotifierBacksOffLookupsOfLateEpochs(t *testing.T) {
+	release := make(chan struct{})
+	notifier := newTestNonceNotifier("", func(ctx context.Context, topicId emissionstypes.TopicId) (lib.BlockHeight, error) {
+		<-release
+		// The epoch ended at 100 but the chain has not moved on yet
+		return 100, nil
+	})
+	ctx := context.Background()
+	notifier.mu.Lock()
+	notifier.waker(ActorRoleWorker, 1)
+	notifier.mu.Unlock()
+
+	// Blocks are not held up by a lookup in flight, nor do they start another one
+	notifier.handleNewBlock(ctx, lib.NewBlockEvent{Height: 100})
+	notifier.handleNewBlock(ctx, lib.NewBlockEvent{Height: 101})
+	assert.True(t, notifier.isLookingUp(1))
+	release <- struct{}{}
+	assert.Eventually(t, func() bool { return !notifier.isLookingUp(1) }, time.Second, 10*time.Millisecond)
+
+	// The epoch found late at block 100, the worker is woken and the epoch looked up again at 101, then at 103, 107, 115 and every 8 blocks
+	lookedUpAt := []lib.BlockHeight{}
+	for height := lib.BlockHeight(101); height <= 130; height++ {
+		notifier.handleNewBlock(ctx, lib.NewBlockEvent{Height: height})
+		if notifier.isLookingUp(1) {
+			lookedUpAt = append(lookedUpAt, height)
+			assert.True(t, wokenWithin(notifier, ActorRoleWorker, 1, time.Second))
+			release <- struct{}{}
+			assert.Eventually(t, func() bool { return !notifier.isLookingUp(1) }, time.Second, 10*time.Millisecond)
+		}
+	}
+	assert.Equal(t, []lib.BlockHeight{101, 103, 107, 115, 123}, lookedUpAt)
+}
+
+func TestNonceNotifierSubscribesToTheActiveRpcNode(t *testing.T) {
+	pool := lib.NewRpcPool([]*lib.RpcEndpoint{{Url: "http://primary:26657"}, {Url: "https://secondary"}}, lib.RpcHealthConfig{})
+	node := &lib.NodeConfig{}
+	node.Wallet.NodeRpc = "http://primary:26657"
+	node.Chain.Rpc = pool
+	notifier, err := NewNonceNotifier(node)
+	require.NoError(t, err)
+
+	websocketUrl, err := notifier.websocketUrl()
+	require.NoError(t, err)
+	assert.Equal(t, "ws://primary:26657/websocket", websocketUrl)
+
+	pool.Report(pool.Active(), errors.New("connection refused"))
+	websocketUrl, err = notifier.websocketUrl()
+	require.NoError(t, err)
+	assert.Equal(t, "wss://secondary/websocket", websocketUrl, "resubscribing to the endpoint failed over to")
+}
+
+func TestNewBlockEventTopicIds(t *testing.T) {
+	var block lib.NewBlockEvent
+	require.NoError(t, json.Unmarshal([]byte(`{"Height": 5, "Events": {"emissions.v4.EventWorkerLastCommitSet.topic_id": ["\"1\"", "\"7\"", "bad"]}}`), &block))
+
+	assert.Equal(t, []emissionstypes.TopicId{1, 7}, block.TopicIds(lib.EVENT_WORKER_LAST_COMMIT_SET))
+	assert.Empty(t, block.TopicIds("emissions.v4.EventReputerLastCommitSet"))
+}
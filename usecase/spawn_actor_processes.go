@@ -2,104 +2,185 @@ package usecase
 
 import (
 	"allora_offchain_node/lib"
+	"context"
+	"errors"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
-	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
 	"github.com/rs/zerolog/log"
 )
 
-func (suite *UseCaseSuite) Spawn() {
+// Run all actor processes until ctx is cancelled.
+// In-flight chain submissions are not aborted together with ctx, but are given up to the drain timeout to complete.
+func (suite *UseCaseSuite) Spawn(ctx context.Context) {
 	var wg sync.WaitGroup
 
-	// Run worker process per topic
-	alreadyStartedWorkerForTopic := make(map[emissionstypes.TopicId]bool)
-	workers := []lib.WorkerConfig{}
-	// parameters := map[string]string{"InferenceEndpoint": "http://47.242.138.93:8000/inference/{Token}", "Token": "ETH"}
-	for i := 1; i < 3; i++ {
-		worker := suite.Node.Worker[0]
-		worker.TopicId = emissionstypes.TopicId(i)
-		workers = append(workers, worker)
-	}
-	for _, worker := range workers {
-		log.Info().Str("------parameters", worker.Parameters["InferenceEndpoint"]).Msg("InferenceEndpoint")
-		if inferenceEndpoint, ok := worker.Parameters["InferenceEndpoint"]; ok {
-			worker.Parameters["InferenceEndpoint"] = strings.Replace(inferenceEndpoint, "localhost", lib.LOCALIP, 1)
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-workCtx.Done():
+			return
 		}
-		if _, ok := alreadyStartedWorkerForTopic[worker.TopicId]; ok {
-			log.Debug().Uint64("topicId", worker.TopicId).Msg("Worker already started for topicId")
-			continue
+		drainTimeout := suite.Node.Shutdown.DrainTimeout()
+		log.Info().Str("drainTimeout", drainTimeout.String()).Msg("Shutdown requested, draining in-flight submissions")
+		timer := time.NewTimer(drainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			log.Warn().Msg("Drain timeout elapsed, aborting in-flight submissions")
+			cancelWork()
+		case <-workCtx.Done():
 		}
-		alreadyStartedWorkerForTopic[worker.TopicId] = true
+	}()
 
+	// Broadcast the transactions of all processes from a single goroutine, until they all finished
+	broadcasterDone := make(chan struct{})
+	if suite.Node.Broadcaster != nil {
+		go func() {
+			defer close(broadcasterDone)
+			suite.Node.Broadcaster.Run(workCtx)
+		}()
+	} else {
+		close(broadcasterDone)
+	}
+
+	// Rank the rpc endpoints by health for as long as processes may query or broadcast
+	if suite.Node.Chain.Rpc != nil {
+		go suite.Node.Chain.Rpc.Run(workCtx)
+	}
+
+	// Wake processes on new blocks instead of having them poll for nonces
+	if suite.Notifier != nil {
 		wg.Add(1)
-		go func(worker lib.WorkerConfig) {
+		go func() {
 			defer wg.Done()
-			suite.runWorkerProcess(worker)
-		}(worker)
+			suite.Notifier.Run(ctx)
+		}()
 	}
 
-	// Run reputer process per topic
-	alreadyStartedReputerForTopic := make(map[emissionstypes.TopicId]bool)
+	// Prune old submissions from the ledger for as long as processes may record them
+	if suite.Ledger != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suite.Ledger.Run(ctx)
+		}()
+	}
 
-	reputers := []lib.ReputerConfig{}
-	// parameters := map[string]string{"InferenceEndpoint": "http://47.242.138.93:8000/inference/{Token}", "Token": "ETH"}
-	// groundTruthParameters := map[string]string{"GroundTruthEndpoint": "http://47.242.138.93:8888/gt/{Token}/{BlockHeight}", "Token": "ETHUSD"}
-	// LossFunctionParameters := lib.LossFunctionParameters{LossFunctionService: "http://47.242.138.93:8001", LossMethodOptions: map[string]string{"loss_method": "sqe"}, IsNeverNegative: nil}
+	// Run worker process per configured topic
+	for _, worker := range suite.Node.Worker {
+		worker = resolveWorkerEndpoints(worker)
+		log.Info().Uint64("topicId", worker.TopicId).Str("InferenceEndpoint", worker.Parameters["InferenceEndpoint"]).Msg("Spawning worker process")
 
-	for i := 1; i < 3; i++ {
-		reputer := suite.Node.Reputer[0]
-		reputer.TopicId = emissionstypes.TopicId(i)
-		reputers = append(reputers, reputer)
+		wg.Add(1)
+		go func(worker lib.WorkerConfig) {
+			defer wg.Done()
+			suite.superviseWorkerProcess(ctx, workCtx, worker)
+		}(worker)
 	}
-	for _, reputer := range reputers {
-		log.Info().Str("------groundTruthParameters", reputer.GroundTruthParameters["InferenceEndpoint"]).Msg("GroundTruthParameters")
 
-		if groundTruthEndpoint, ok := reputer.GroundTruthParameters["InferenceEndpoint"]; ok {
-			reputer.GroundTruthParameters["GroundTruthEndpoint"] = strings.Replace(groundTruthEndpoint, "localhost", lib.LOCALIP, 1)
-		}
-		if _, ok := alreadyStartedReputerForTopic[reputer.TopicId]; ok {
-			log.Debug().Uint64("topicId", reputer.TopicId).Msg("Reputer already started for topicId")
-			continue
-		}
-		alreadyStartedReputerForTopic[reputer.TopicId] = true
+	// Run reputer process per configured topic
+	for _, reputer := range suite.Node.Reputer {
+		reputer = resolveReputerEndpoints(reputer)
+		log.Info().Uint64("topicId", reputer.TopicId).Str("GroundTruthEndpoint", reputer.GroundTruthParameters["GroundTruthEndpoint"]).Msg("Spawning reputer process")
 
 		wg.Add(1)
 		go func(reputer lib.ReputerConfig) {
 			defer wg.Done()
-			suite.runReputerProcess(reputer)
+			suite.superviseReputerProcess(ctx, workCtx, reputer)
 		}(reputer)
 	}
 
+	// Run topic discovery, which spawns and tears down processes for active topics on chain
+	if suite.Node.TopicDiscovery.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suite.runTopicDiscovery(ctx, workCtx, &wg)
+		}()
+	}
+
 	// Wait for all goroutines to finish
 	wg.Wait()
+	cancelWork()
+	<-broadcasterDone
+
+	log.Info().Msg("All processes finished")
+}
+
+// Point endpoints configured on localhost at the IP of this host
+func resolveWorkerEndpoints(worker lib.WorkerConfig) lib.WorkerConfig {
+	if inferenceEndpoint, ok := worker.Parameters["InferenceEndpoint"]; ok {
+		worker.Parameters["InferenceEndpoint"] = strings.Replace(inferenceEndpoint, "localhost", lib.LOCALIP, 1)
+	}
+	return worker
+}
+
+// Point endpoints configured on localhost at the IP of this host
+func resolveReputerEndpoints(reputer lib.ReputerConfig) lib.ReputerConfig {
+	if groundTruthEndpoint, ok := reputer.GroundTruthParameters["GroundTruthEndpoint"]; ok {
+		reputer.GroundTruthParameters["GroundTruthEndpoint"] = strings.Replace(groundTruthEndpoint, "localhost", lib.LOCALIP, 1)
+	}
+	return reputer
+}
+
+// Run the worker process for a topic until ctx is cancelled, restarting it with backoff whenever it fails
+func (suite *UseCaseSuite) superviseWorkerProcess(ctx context.Context, workCtx context.Context, worker lib.WorkerConfig) {
+	suite.Supervisor.Supervise(ctx, ActorRoleWorker, worker.TopicId, func(ctx context.Context, actor *SupervisedActor) error {
+		return suite.runWorkerProcess(ctx, workCtx, worker, actor)
+	})
+}
 
-	log.Info().Msg("==============================================================All processes finished")
+// Run the reputer process for a topic until ctx is cancelled, restarting it with backoff whenever it fails
+func (suite *UseCaseSuite) superviseReputerProcess(ctx context.Context, workCtx context.Context, reputer lib.ReputerConfig) {
+	suite.Supervisor.Supervise(ctx, ActorRoleReputer, reputer.TopicId, func(ctx context.Context, actor *SupervisedActor) error {
+		return suite.runReputerProcess(ctx, workCtx, reputer, actor)
+	})
 }
 
-func (suite *UseCaseSuite) runWorkerProcess(worker lib.WorkerConfig) {
+// Run the worker loop for a topic until ctx is cancelled.
+// Registration and payload submissions run under workCtx, so they are not interrupted mid-transaction.
+// Its state is recorded through the supervised actor. Returns an error if the worker could not be registered.
+func (suite *UseCaseSuite) runWorkerProcess(ctx context.Context, workCtx context.Context, worker lib.WorkerConfig, actor *SupervisedActor) error {
 	log.Info().Uint64("topicId", worker.TopicId).Msg("Running worker process for topic")
 
-	registered := suite.Node.RegisterWorkerIdempotently(worker)
+	actor.SetState(ActorStateRegistering)
+	registered := suite.Node.RegisterWorkerIdempotently(workCtx, worker)
 	if !registered {
 		log.Error().Uint64("topicId", worker.TopicId).Msg("Failed to register worker for topic")
-		return
+		return errors.New("failed to register worker for topic")
 	}
+	actor.SetState(ActorStateRunning)
 
 	latestNonceHeightActedUpon := int64(0)
 	for {
-		latestOpenWorkerNonce, err := suite.Node.GetLatestOpenWorkerNonceByTopicId(worker.TopicId)
+		latestOpenWorkerNonce, err := suite.Node.GetLatestOpenWorkerNonceByTopicId(ctx, worker.TopicId)
 		if err != nil {
 			log.Warn().Err(err).Uint64("topicId", worker.TopicId).Msg("Error getting latest open worker nonce on topic - node availability issue?")
 		} else {
-			if latestOpenWorkerNonce.BlockHeight > latestNonceHeightActedUpon {
+			if latestOpenWorkerNonce.BlockHeight > latestNonceHeightActedUpon && suite.isSubmitted(ActorRoleWorker, worker.TopicId, latestOpenWorkerNonce.BlockHeight) {
+				log.Info().Uint64("topicId", worker.TopicId).Int64("BlockHeight", latestOpenWorkerNonce.BlockHeight).Msg("Worker payload already submitted for nonce, skipping")
+				latestNonceHeightActedUpon = latestOpenWorkerNonce.BlockHeight
+			} else if latestOpenWorkerNonce.BlockHeight > latestNonceHeightActedUpon {
 				log.Debug().Uint64("topicId", worker.TopicId).Int64("BlockHeight", latestOpenWorkerNonce.BlockHeight).Msg("Building and committing worker payload for topic")
 
-				err := suite.BuildCommitWorkerPayload(worker, latestOpenWorkerNonce)
-				if err != nil {
-					log.Error().Err(err).Uint64("topicId", worker.TopicId).Int64("BlockHeight", latestOpenWorkerNonce.BlockHeight).Msg("Error building and committing worker payload for topic")
+				// Stop computing and submitting once the payload can no longer make it in time
+				nonceCtx, cancel := suite.nonceContext(workCtx, ActorRoleWorker, worker.TopicId, latestOpenWorkerNonce.BlockHeight)
+				retry := false
+				if !suite.checkMissedNonce(nonceCtx, ActorRoleWorker, worker.TopicId, latestOpenWorkerNonce.BlockHeight) {
+					err := suite.BuildCommitWorkerPayload(nonceCtx, worker, latestOpenWorkerNonce)
+					if err != nil && !suite.checkMissedNonce(nonceCtx, ActorRoleWorker, worker.TopicId, latestOpenWorkerNonce.BlockHeight) {
+						retry = logPayloadError(err, ActorRoleWorker, worker.TopicId, latestOpenWorkerNonce.BlockHeight)
+					}
+				}
+				cancel()
+				if !retry {
+					latestNonceHeightActedUpon = latestOpenWorkerNonce.BlockHeight
 				}
-				latestNonceHeightActedUpon = latestOpenWorkerNonce.BlockHeight
 			} else {
 				log.Debug().Uint64("topicId", worker.TopicId).
 					Int64("latestOpenWorkerNonce", latestOpenWorkerNonce.BlockHeight).
@@ -107,37 +188,124 @@ func (suite *UseCaseSuite) runWorkerProcess(worker lib.WorkerConfig) {
 					Msg("No new worker nonce found")
 			}
 		}
-		suite.Wait(worker.LoopSeconds)
+		if !suite.WaitForNonce(ctx, ActorRoleWorker, worker.TopicId, worker.LoopSeconds) {
+			log.Info().Uint64("topicId", worker.TopicId).Msg("Stopping worker process for topic")
+			return nil
+		}
 	}
 }
 
-func (suite *UseCaseSuite) runReputerProcess(reputer lib.ReputerConfig) {
+// Run the reputer loop for a topic until ctx is cancelled.
+// Registration, staking and payload submissions run under workCtx, so they are not interrupted mid-transaction.
+// Its state is recorded through the supervised actor. Returns an error if the reputer could not be registered or sufficiently staked.
+func (suite *UseCaseSuite) runReputerProcess(ctx context.Context, workCtx context.Context, reputer lib.ReputerConfig, actor *SupervisedActor) error {
 	log.Debug().Uint64("topicId", reputer.TopicId).Msg("Running reputer process for topic")
 
-	registeredAndStaked := suite.Node.RegisterAndStakeReputerIdempotently(reputer)
-	if !registeredAndStaked {
-		log.Error().Uint64("topicId", reputer.TopicId).Msg("Failed to register or sufficiently stake reputer for topic")
-		return
+	actor.SetState(ActorStateRegistering)
+	if !suite.Node.RegisterReputerIdempotently(workCtx, reputer) {
+		log.Error().Uint64("topicId", reputer.TopicId).Msg("Failed to register reputer for topic")
+		return errors.New("failed to register reputer for topic")
 	}
+	actor.SetState(ActorStateStaking)
+	if !suite.Node.StakeReputerIdempotently(workCtx, reputer) {
+		log.Error().Uint64("topicId", reputer.TopicId).Msg("Failed to sufficiently stake reputer for topic")
+		return errors.New("failed to sufficiently stake reputer for topic")
+	}
+	actor.SetState(ActorStateRunning)
 
-	latestNonceHeightActedUpon := int64(0)
+	// Nonces acted upon that are still unfulfilled on chain. Only those whose sources were unavailable are retried,
+	// as a payload that failed otherwise, e.g. was rejected by the chain, would fail again and pay fees each time.
+	actedUpon := make(map[lib.BlockHeight]bool)
 	for {
-		latestOpenReputerNonce, err := suite.Node.GetOldestReputerNonceByTopicId(reputer.TopicId)
+		unfulfilledNonces, err := suite.Node.GetUnfulfilledReputerNonceHeights(ctx, reputer.TopicId)
 		if err != nil {
-			log.Warn().Err(err).Uint64("topicId", reputer.TopicId).Int64("BlockHeight", latestOpenReputerNonce).Msg("Error getting latest open reputer nonce on topic - node availability issue?")
+			log.Warn().Err(err).Uint64("topicId", reputer.TopicId).Msg("Error getting unfulfilled reputer nonces on topic - node availability issue?")
 		} else {
-			if latestOpenReputerNonce > latestNonceHeightActedUpon {
-				log.Debug().Uint64("topicId", reputer.TopicId).Int64("BlockHeight", latestOpenReputerNonce).Msg("Building and committing reputer payload for topic")
-
-				err := suite.BuildCommitReputerPayload(reputer, latestOpenReputerNonce)
-				if err != nil {
-					log.Error().Err(err).Uint64("topicId", reputer.TopicId).Msg("Error building and committing reputer payload for topic")
+			// The chain rejects payloads until the ground truth of a nonce is due, so leave the nonces whose window
+			// has not opened yet out, before capping, and wake up once the first of them opens
+			windowOpened := suite.reputerWindowOpened(ctx, reputer.TopicId)
+			wakeAt := lib.BlockHeight(0)
+			opened := func(nonce lib.BlockHeight) bool {
+				opens, ok := windowOpened(nonce)
+				if !ok {
+					log.Debug().Uint64("topicId", reputer.TopicId).Int64("BlockHeight", nonce).Int64("opens", opens).Msg("Submission window of reputer nonce not open yet, waiting for it")
+					if wakeAt == 0 || opens < wakeAt {
+						wakeAt = opens
+					}
 				}
-				latestNonceHeightActedUpon = latestOpenReputerNonce
-			} else {
+				return ok
+			}
+			nonces := selectReputerNonces(unfulfilledNonces, actedUpon, opened, reputer.ReputerNonceOrder(), reputer.MaxReputerNoncesPerCycle())
+			if wakeAt != 0 && suite.Notifier != nil {
+				suite.Notifier.WakeAt(ActorRoleReputer, reputer.TopicId, wakeAt)
+			}
+			if len(nonces) == 0 {
 				log.Debug().Uint64("topicId", reputer.TopicId).Msg("No new reputer nonce found")
 			}
+			for _, nonce := range nonces {
+				// Leave catching up on the remaining nonces to the next run
+				if ctx.Err() != nil {
+					break
+				}
+				if suite.isSubmitted(ActorRoleReputer, reputer.TopicId, nonce) {
+					log.Info().Uint64("topicId", reputer.TopicId).Int64("BlockHeight", nonce).Msg("Reputer payload already submitted for nonce, skipping")
+					actedUpon[nonce] = true
+					continue
+				}
+				log.Debug().Uint64("topicId", reputer.TopicId).Int64("BlockHeight", nonce).Msg("Building and committing reputer payload for topic")
+
+				// Stop computing and submitting once the payload can no longer make it in time
+				nonceCtx, cancel := suite.nonceContext(workCtx, ActorRoleReputer, reputer.TopicId, nonce)
+				retry := false
+				if !suite.checkMissedNonce(nonceCtx, ActorRoleReputer, reputer.TopicId, nonce) {
+					err := suite.BuildCommitReputerPayload(nonceCtx, reputer, nonce)
+					if err != nil && !suite.checkMissedNonce(nonceCtx, ActorRoleReputer, reputer.TopicId, nonce) {
+						retry = logPayloadError(err, ActorRoleReputer, reputer.TopicId, nonce)
+					}
+				}
+				cancel()
+				if !retry {
+					actedUpon[nonce] = true
+				}
+			}
+			// Forget the nonces that have since been fulfilled or expired
+			for nonce := range actedUpon {
+				if !slices.Contains(unfulfilledNonces, nonce) {
+					delete(actedUpon, nonce)
+				}
+			}
+		}
+		if !suite.WaitForNonce(ctx, ActorRoleReputer, reputer.TopicId, reputer.LoopSeconds) {
+			log.Info().Uint64("topicId", reputer.TopicId).Msg("Stopping reputer process for topic")
+			return nil
+		}
+	}
+}
+
+// Log the error building and committing the payload of the actor for the nonce.
+// Returns true if the nonce is worth acting upon again on the next loop, as the source of a value was unavailable.
+func logPayloadError(err error, role ActorRole, topicId uint64, nonce lib.BlockHeight) bool {
+	if lib.IsSourceUnavailable(err) {
+		log.Warn().Err(err).Str("role", string(role)).Uint64("topicId", topicId).Int64("BlockHeight", nonce).Msg("Source unavailable building payload for topic, retrying on the next loop")
+		return true
+	}
+	log.Error().Err(err).Str("role", string(role)).Uint64("topicId", topicId).Int64("BlockHeight", nonce).Msg("Error building and committing payload for topic")
+	return false
+}
+
+// Unfulfilled nonces, given oldest first, not yet acted upon and whose window opened, in the given order and capped at max
+func selectReputerNonces(unfulfilledNonces []lib.BlockHeight, actedUpon map[lib.BlockHeight]bool, opened func(lib.BlockHeight) bool, order string, max int64) []lib.BlockHeight {
+	nonces := []lib.BlockHeight{}
+	for _, nonce := range unfulfilledNonces {
+		if !actedUpon[nonce] && opened(nonce) {
+			nonces = append(nonces, nonce)
 		}
-		suite.Wait(reputer.LoopSeconds)
 	}
+	if order == lib.ReputerNonceOrderNewestFirst {
+		slices.Reverse(nonces)
+	}
+	if int64(len(nonces)) > max {
+		nonces = nonces[:max]
+	}
+	return nonces
 }
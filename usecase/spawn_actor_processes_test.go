@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"errors"
+	"testing"
+
+	errorsmod "cosmossdk.io/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func allOpened(lib.BlockHeight) bool { return true }
+
+func TestSelectReputerNonces(t *testing.T) {
+	unfulfilled := []lib.BlockHeight{100, 200, 300, 400}
+	actedUpon := map[lib.BlockHeight]bool{200: true}
+
+	tests := []struct {
+		name     string
+		order    string
+		max      int64
+		expected []lib.BlockHeight
+	}{
+		{"oldest first", lib.ReputerNonceOrderOldestFirst, 10, []lib.BlockHeight{100, 300, 400}},
+		{"newest first", lib.ReputerNonceOrderNewestFirst, 10, []lib.BlockHeight{400, 300, 100}},
+		{"oldest first capped", lib.ReputerNonceOrderOldestFirst, 2, []lib.BlockHeight{100, 300}},
+		{"newest first capped", lib.ReputerNonceOrderNewestFirst, 1, []lib.BlockHeight{400}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, selectReputerNonces(unfulfilled, actedUpon, allOpened, tt.order, tt.max))
+		})
+	}
+
+	assert.Empty(t, selectReputerNonces([]lib.BlockHeight{200}, actedUpon, allOpened, lib.ReputerNonceOrderOldestFirst, 10))
+}
+
+func TestSelectReputerNoncesCapsOnlyOpenedNonces(t *testing.T) {
+	// Epochs of 10 blocks, windows opening 60 blocks after the nonce, at block 1100
+	unfulfilled := []lib.BlockHeight{1000, 1010, 1020, 1030, 1040, 1050}
+	opened := func(nonce lib.BlockHeight) bool { return nonce+60 <= 1100 }
+	assert.Equal(t, []lib.BlockHeight{1040, 1030}, selectReputerNonces(unfulfilled, map[lib.BlockHeight]bool{}, opened, lib.ReputerNonceOrderNewestFirst, 2))
+}
+
+func TestOnlyReputerNoncesWithUnavailableSourcesAreActedUponAgain(t *testing.T) {
+	unfulfilled := []lib.BlockHeight{100, 200, 300}
+	actedUpon := map[lib.BlockHeight]bool{}
+	outcomes := map[lib.BlockHeight]error{
+		100: nil,
+		200: errors.New("error code: '5' msg: 'invalid reputer value bundle'"),
+		300: &lib.SourceUnavailableError{Source: "http://truth:8000", Err: lib.ErrCircuitOpen},
+	}
+	for _, nonce := range selectReputerNonces(unfulfilled, actedUpon, allOpened, lib.ReputerNonceOrderOldestFirst, 10) {
+		if err := outcomes[nonce]; err == nil || !logPayloadError(err, ActorRoleReputer, 1, nonce) {
+			actedUpon[nonce] = true
+		}
+	}
+	assert.Equal(t, []lib.BlockHeight{300}, selectReputerNonces(unfulfilled, actedUpon, allOpened, lib.ReputerNonceOrderOldestFirst, 10))
+}
+
+func TestLogPayloadErrorRetriesUnavailableSources(t *testing.T) {
+	unavailable := &lib.SourceUnavailableError{Source: "http://models:8000", Err: lib.ErrCircuitOpen}
+	assert.True(t, logPayloadError(errorsmod.Wrapf(unavailable, "Error computing inference"), ActorRoleWorker, 1, 100))
+	assert.True(t, logPayloadError(errors.Join(errors.New("loss 1"), unavailable), ActorRoleReputer, 1, 100))
+
+	invalid := &lib.InvalidValueError{Source: "http://models:8000", Value: "NaN", Err: errors.New("not a decimal")}
+	assert.False(t, logPayloadError(errorsmod.Wrapf(invalid, "Error computing inference"), ActorRoleWorker, 1, 100))
+}
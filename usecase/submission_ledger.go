@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Payload message that can be hashed for the submission ledger
+type ledgerMsg interface {
+	sdktypes.Msg
+	Marshal() ([]byte, error)
+}
+
+// True if the ledger records the payload of the actor for the nonce as submitted.
+// Ledger errors are logged and treated as not submitted, so nonces are never skipped because of them.
+func (suite *UseCaseSuite) isSubmitted(role ActorRole, topicId emissionstypes.TopicId, nonce lib.BlockHeight) bool {
+	if suite.Ledger == nil {
+		return false
+	}
+	submission, err := suite.Ledger.Get(string(role), topicId, nonce)
+	if err != nil {
+		log.Warn().Err(err).Str("role", string(role)).Uint64("topicId", topicId).Int64("nonce", nonce).Msg("Could not read submission ledger")
+		return false
+	}
+	return submission != nil && submission.Status == lib.SubmissionStatusSubmitted
+}
+
+// Record the submission in the ledger, logging rather than failing on ledger errors
+func (suite *UseCaseSuite) recordSubmission(submission lib.Submission) {
+	if suite.Ledger == nil {
+		return
+	}
+	if err := suite.Ledger.Put(submission); err != nil {
+		log.Warn().Err(err).Str("role", submission.Role).Uint64("topicId", submission.TopicId).Int64("nonce", submission.Nonce).Msg("Could not update submission ledger")
+	}
+}
+
+// Send the payload of the actor for the nonce to the chain, recording the submission in the ledger
+func (suite *UseCaseSuite) sendPayload(ctx context.Context, role ActorRole, topicId emissionstypes.TopicId, nonce lib.BlockHeight, req ledgerMsg, infoMsg string) error {
+	submission := lib.Submission{
+		Role:    string(role),
+		TopicId: topicId,
+		Nonce:   nonce,
+		Status:  lib.SubmissionStatusPending,
+	}
+	payloadHash, err := lib.PayloadHash(req)
+	if err != nil {
+		log.Warn().Err(err).Uint64("topicId", topicId).Msg("Could not hash payload for submission ledger")
+	}
+	submission.PayloadHash = payloadHash
+	suite.recordSubmission(submission)
+
+	res, err := suite.Node.SendDataWithPriority(ctx, req, infoMsg, lib.TxPriorityHigh)
+	if err != nil {
+		submission.Status = lib.SubmissionStatusFailed
+		submission.Error = err.Error()
+		suite.recordSubmission(submission)
+		return err
+	}
+	submission.Status = lib.SubmissionStatusSubmitted
+	if res != nil {
+		submission.TxHash = res.TxHash
+		submission.Fees = res.Fees
+		if res.Inclusion != nil {
+			submission.Height = res.Inclusion.Height
+			if res.Inclusion.FeePaid != "" {
+				submission.Fees = res.Inclusion.FeePaid
+			}
+		}
+	}
+	suite.recordSubmission(submission)
+	return nil
+}
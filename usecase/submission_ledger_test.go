@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmissionLedgerSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	ledger, err := lib.OpenSubmissionLedger(path, 0)
+	require.NoError(t, err)
+	suite := &UseCaseSuite{Ledger: ledger}
+
+	suite.recordSubmission(lib.Submission{Role: string(ActorRoleWorker), TopicId: 1, Nonce: 100, PayloadHash: "abc", Status: lib.SubmissionStatusPending})
+	assert.False(t, suite.isSubmitted(ActorRoleWorker, 1, 100), "pending submissions are retried")
+	suite.recordSubmission(lib.Submission{Role: string(ActorRoleWorker), TopicId: 1, Nonce: 100, PayloadHash: "abc", TxHash: "ABCDEF", Fees: "20uallo", Status: lib.SubmissionStatusSubmitted})
+	suite.recordSubmission(lib.Submission{Role: string(ActorRoleReputer), TopicId: 1, Nonce: 90, Status: lib.SubmissionStatusFailed, Error: "out of gas"})
+	require.NoError(t, ledger.Close())
+
+	ledger, err = lib.OpenSubmissionLedger(path, 0)
+	require.NoError(t, err)
+	defer ledger.Close()
+	suite = &UseCaseSuite{Ledger: ledger}
+
+	assert.True(t, suite.isSubmitted(ActorRoleWorker, 1, 100))
+	assert.False(t, suite.isSubmitted(ActorRoleReputer, 1, 100), "roles are recorded separately")
+	assert.False(t, suite.isSubmitted(ActorRoleWorker, 2, 100), "topics are recorded separately")
+	assert.False(t, suite.isSubmitted(ActorRoleReputer, 1, 90), "failed submissions are retried")
+
+	submission, err := ledger.Get(string(ActorRoleWorker), 1, 100)
+	require.NoError(t, err)
+	require.NotNil(t, submission)
+	assert.Equal(t, "ABCDEF", submission.TxHash)
+	assert.Equal(t, "20uallo", submission.Fees)
+	assert.Equal(t, "abc", submission.PayloadHash)
+	assert.False(t, submission.UpdatedAt.Before(submission.CreatedAt))
+}
+
+func TestSubmissionLedgerPrunesOldSubmissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	ledger, err := lib.OpenSubmissionLedger(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, ledger.Put(lib.Submission{Role: string(ActorRoleWorker), TopicId: 1, Nonce: 100, Status: lib.SubmissionStatusSubmitted}))
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	require.NoError(t, ledger.Put(lib.Submission{Role: string(ActorRoleWorker), TopicId: 1, Nonce: 200, Status: lib.SubmissionStatusSubmitted}))
+
+	pruned, err := ledger.Prune(cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	submission, err := ledger.Get(string(ActorRoleWorker), 1, 100)
+	require.NoError(t, err)
+	assert.Nil(t, submission)
+	submission, err = ledger.Get(string(ActorRoleWorker), 1, 200)
+	require.NoError(t, err)
+	assert.NotNil(t, submission)
+	require.NoError(t, ledger.Close())
+
+	// Submissions older than the retention are pruned when the ledger is opened
+	time.Sleep(10 * time.Millisecond)
+	ledger, err = lib.OpenSubmissionLedger(path, 5*time.Millisecond)
+	require.NoError(t, err)
+	defer ledger.Close()
+	submission, err = ledger.Get(string(ActorRoleWorker), 1, 200)
+	require.NoError(t, err)
+	assert.Nil(t, submission)
+}
+
+func TestSubmissionLedgerIsOptional(t *testing.T) {
+	suite := &UseCaseSuite{}
+	suite.recordSubmission(lib.Submission{Role: string(ActorRoleWorker), TopicId: 1, Nonce: 100, Status: lib.SubmissionStatusSubmitted})
+	assert.False(t, suite.isSubmitted(ActorRoleWorker, 1, 100))
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"errors"
+	"time"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Context for building and submitting the payload of the actor for the nonce, done once the payload can no longer
+// make it into the submission window of the nonce. Without a deadline if the window cannot be determined.
+func (suite *UseCaseSuite) nonceContext(ctx context.Context, role ActorRole, topicId emissionstypes.TopicId, nonce lib.BlockHeight) (context.Context, context.CancelFunc) {
+	deadline, err := suite.submissionDeadline(ctx, role, topicId, nonce)
+	if err != nil {
+		log.Warn().Err(err).Str("role", string(role)).Uint64("topicId", topicId).Int64("nonce", nonce).Msg("Could not determine submission window of nonce, submitting without a deadline")
+		return context.WithCancel(ctx)
+	}
+	log.Debug().Str("role", string(role)).Uint64("topicId", topicId).Int64("nonce", nonce).Time("deadline", deadline).Msg("Submission deadline of nonce")
+	return context.WithDeadline(ctx, deadline)
+}
+
+// Time by which the payload of the actor for the nonce must be broadcast, from the topic parameters and latest block
+func (suite *UseCaseSuite) submissionDeadline(ctx context.Context, role ActorRole, topicId emissionstypes.TopicId, nonce lib.BlockHeight) (time.Time, error) {
+	topic, err := suite.Node.GetTopic(ctx, topicId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	height, err := suite.Node.GetLatestBlockHeight(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	window := lib.WorkerSubmissionWindow(topic, nonce)
+	if role == ActorRoleReputer {
+		window = lib.ReputerSubmissionWindow(topic, nonce)
+	}
+	return window.Deadline(height, time.Now()), nil
+}
+
+// Tells the block at which the submission window of a reputer nonce of the topic opens, and whether it has at the
+// latest block. The topic and latest block are queried once, on first use. Windows are assumed open if they cannot be
+// determined, leaving it to the chain to reject early payloads.
+func (suite *UseCaseSuite) reputerWindowOpened(ctx context.Context, topicId emissionstypes.TopicId) func(nonce lib.BlockHeight) (lib.BlockHeight, bool) {
+	var topic *emissionstypes.Topic
+	var height lib.BlockHeight
+	var err error
+	queried := false
+	return func(nonce lib.BlockHeight) (lib.BlockHeight, bool) {
+		if !queried {
+			queried = true
+			topic, err = suite.Node.GetTopic(ctx, topicId)
+			if err == nil {
+				height, err = suite.Node.GetLatestBlockHeight(ctx)
+			}
+			if err != nil {
+				log.Warn().Err(err).Uint64("topicId", topicId).Msg("Could not determine submission windows of reputer nonces, assuming them open")
+			}
+		}
+		if err != nil {
+			return nonce, true
+		}
+		window := lib.ReputerSubmissionWindow(topic, nonce)
+		return window.Opens, window.HasOpened(height)
+	}
+}
+
+// Count the nonce as missed if its submission window closed before its payload made it. Returns true if it did.
+func (suite *UseCaseSuite) checkMissedNonce(nonceCtx context.Context, role ActorRole, topicId emissionstypes.TopicId, nonce lib.BlockHeight) bool {
+	if !errors.Is(nonceCtx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	log.Warn().Str("role", string(role)).Uint64("topicId", topicId).Int64("nonce", nonce).Msg("Submission window of nonce closed before its payload was submitted")
+	counter := lib.WorkerMissedNonceCount
+	if role == ActorRoleReputer {
+		counter = lib.ReputerMissedNonceCount
+	}
+	suite.Metrics.IncrementMetricsCounter(counter, suite.Node.Chain.Address, topicId)
+	return true
+}
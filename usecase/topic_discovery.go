@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"context"
+	"regexp"
+	"sync"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Processes spawned for discovered topics, by topic, so they can be torn down when the topic goes inactive
+type discoveredActors struct {
+	mu       sync.Mutex
+	workers  map[emissionstypes.TopicId]context.CancelFunc
+	reputers map[emissionstypes.TopicId]context.CancelFunc
+}
+
+// Poll the chain for active topics and keep one worker and/or reputer process running per discovered topic.
+// Topics explicitly configured in Worker or Reputer are left to their own processes.
+func (suite *UseCaseSuite) runTopicDiscovery(ctx context.Context, workCtx context.Context, wg *sync.WaitGroup) {
+	discovery := suite.Node.TopicDiscovery
+	log.Info().Int64("loopSeconds", discovery.LoopSeconds).Msg("Running topic discovery")
+
+	actors := &discoveredActors{
+		workers:  make(map[emissionstypes.TopicId]context.CancelFunc),
+		reputers: make(map[emissionstypes.TopicId]context.CancelFunc),
+	}
+
+	configuredWorkerTopics := make(map[emissionstypes.TopicId]bool)
+	for _, worker := range suite.Node.Worker {
+		configuredWorkerTopics[worker.TopicId] = true
+	}
+	configuredReputerTopics := make(map[emissionstypes.TopicId]bool)
+	for _, reputer := range suite.Node.Reputer {
+		configuredReputerTopics[reputer.TopicId] = true
+	}
+
+	tracker := lib.NewActiveTopicTracker(&suite.Node)
+	for {
+		topics, err := tracker.ActiveTopics(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Error getting active topics - node availability issue?")
+		} else {
+			discoveredTopicIds := filterDiscoveredTopics(discovery, topics)
+			log.Debug().Interface("topicIds", discoveredTopicIds).Msg("Discovered active topics")
+
+			if discovery.WorkerTemplate != nil {
+				suite.syncDiscoveredWorkers(ctx, workCtx, wg, actors, discoveredTopicIds, configuredWorkerTopics)
+			}
+			if discovery.ReputerTemplate != nil {
+				suite.syncDiscoveredReputers(ctx, workCtx, wg, actors, discoveredTopicIds, configuredReputerTopics)
+			}
+		}
+
+		if !suite.Wait(ctx, discovery.LoopSeconds) {
+			log.Info().Msg("Stopping topic discovery")
+			return
+		}
+	}
+}
+
+// Start a worker process for each newly discovered topic and stop those whose topic is no longer discovered
+func (suite *UseCaseSuite) syncDiscoveredWorkers(
+	ctx context.Context,
+	workCtx context.Context,
+	wg *sync.WaitGroup,
+	actors *discoveredActors,
+	topicIds []emissionstypes.TopicId,
+	configuredTopics map[emissionstypes.TopicId]bool,
+) {
+	actors.mu.Lock()
+	defer actors.mu.Unlock()
+
+	wanted := make(map[emissionstypes.TopicId]bool)
+	for _, topicId := range topicIds {
+		if configuredTopics[topicId] {
+			continue
+		}
+		wanted[topicId] = true
+		if _, ok := actors.workers[topicId]; ok {
+			continue
+		}
+
+		worker := resolveWorkerEndpoints(workerFromTemplate(*suite.Node.TopicDiscovery.WorkerTemplate, topicId))
+		log.Info().Uint64("topicId", topicId).Msg("Spawning worker process for discovered topic")
+
+		actorCtx, cancel := context.WithCancel(ctx)
+		actors.workers[topicId] = cancel
+		wg.Add(1)
+		go func(worker lib.WorkerConfig) {
+			defer wg.Done()
+			suite.superviseWorkerProcess(actorCtx, workCtx, worker)
+		}(worker)
+	}
+
+	for topicId, cancel := range actors.workers {
+		if !wanted[topicId] {
+			log.Info().Uint64("topicId", topicId).Msg("Topic no longer discovered, tearing down worker process")
+			cancel()
+			delete(actors.workers, topicId)
+		}
+	}
+}
+
+// Start a reputer process for each newly discovered topic and stop those whose topic is no longer discovered
+func (suite *UseCaseSuite) syncDiscoveredReputers(
+	ctx context.Context,
+	workCtx context.Context,
+	wg *sync.WaitGroup,
+	actors *discoveredActors,
+	topicIds []emissionstypes.TopicId,
+	configuredTopics map[emissionstypes.TopicId]bool,
+) {
+	actors.mu.Lock()
+	defer actors.mu.Unlock()
+
+	wanted := make(map[emissionstypes.TopicId]bool)
+	for _, topicId := range topicIds {
+		if configuredTopics[topicId] {
+			continue
+		}
+		wanted[topicId] = true
+		if _, ok := actors.reputers[topicId]; ok {
+			continue
+		}
+
+		reputer := resolveReputerEndpoints(reputerFromTemplate(*suite.Node.TopicDiscovery.ReputerTemplate, topicId))
+		log.Info().Uint64("topicId", topicId).Msg("Spawning reputer process for discovered topic")
+
+		actorCtx, cancel := context.WithCancel(ctx)
+		actors.reputers[topicId] = cancel
+		wg.Add(1)
+		go func(reputer lib.ReputerConfig) {
+			defer wg.Done()
+			suite.superviseReputerProcess(actorCtx, workCtx, reputer)
+		}(reputer)
+	}
+
+	for topicId, cancel := range actors.reputers {
+		if !wanted[topicId] {
+			log.Info().Uint64("topicId", topicId).Msg("Topic no longer discovered, tearing down reputer process")
+			cancel()
+			delete(actors.reputers, topicId)
+		}
+	}
+}
+
+// Select the ids of the topics that pass the allow list, deny list and metadata filters
+func filterDiscoveredTopics(discovery lib.TopicDiscoveryConfig, topics []*emissionstypes.Topic) []emissionstypes.TopicId {
+	var metadataRegex *regexp.Regexp
+	if discovery.MetadataRegex != "" {
+		var err error
+		metadataRegex, err = regexp.Compile(discovery.MetadataRegex)
+		if err != nil {
+			log.Error().Err(err).Str("metadataRegex", discovery.MetadataRegex).Msg("Invalid topic discovery metadata regex, discovering no topics")
+			return nil
+		}
+	}
+
+	allowed := make(map[emissionstypes.TopicId]bool)
+	for _, topicId := range discovery.AllowTopicIds {
+		allowed[topicId] = true
+	}
+	denied := make(map[emissionstypes.TopicId]bool)
+	for _, topicId := range discovery.DenyTopicIds {
+		denied[topicId] = true
+	}
+
+	topicIds := []emissionstypes.TopicId{}
+	for _, topic := range topics {
+		if topic == nil {
+			continue
+		}
+		if len(allowed) > 0 && !allowed[topic.Id] {
+			continue
+		}
+		if denied[topic.Id] {
+			continue
+		}
+		if metadataRegex != nil && !metadataRegex.MatchString(topic.Metadata) {
+			continue
+		}
+		topicIds = append(topicIds, topic.Id)
+	}
+	return topicIds
+}
+
+// Clone the worker template for the given topic, so that no two processes share parameter maps
+func workerFromTemplate(template lib.WorkerConfig, topicId emissionstypes.TopicId) lib.WorkerConfig {
+	worker := template
+	worker.TopicId = topicId
+	worker.Parameters = copyStringMap(template.Parameters)
+	worker.InferenceGuards.FallbackParameters = copyStringMap(template.InferenceGuards.FallbackParameters)
+	return worker
+}
+
+// Clone the reputer template for the given topic, so that no two processes share parameter maps
+func reputerFromTemplate(template lib.ReputerConfig, topicId emissionstypes.TopicId) lib.ReputerConfig {
+	reputer := template
+	reputer.TopicId = topicId
+	reputer.GroundTruthParameters = copyStringMap(template.GroundTruthParameters)
+	reputer.LossFunctionParameters.LossMethodOptions = copyStringMap(template.LossFunctionParameters.LossMethodOptions)
+	return reputer
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
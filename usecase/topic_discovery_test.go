@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"allora_offchain_node/lib"
+	"testing"
+
+	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterDiscoveredTopics(t *testing.T) {
+	topics := []*emissionstypes.Topic{
+		{Id: 1, Metadata: "ETH 10min Prediction"},
+		{Id: 2, Metadata: "ETH 24h Prediction"},
+		{Id: 3, Metadata: "BTC 10min Prediction"},
+		nil,
+		{Id: 4, Metadata: "SOL 10min Prediction"},
+	}
+
+	tests := []struct {
+		name      string
+		discovery lib.TopicDiscoveryConfig
+		expected  []emissionstypes.TopicId
+	}{
+		{
+			name:      "No filters - all topics",
+			discovery: lib.TopicDiscoveryConfig{},
+			expected:  []emissionstypes.TopicId{1, 2, 3, 4},
+		},
+		{
+			name:      "Allow list",
+			discovery: lib.TopicDiscoveryConfig{AllowTopicIds: []emissionstypes.TopicId{2, 4, 7}},
+			expected:  []emissionstypes.TopicId{2, 4},
+		},
+		{
+			name:      "Deny list",
+			discovery: lib.TopicDiscoveryConfig{DenyTopicIds: []emissionstypes.TopicId{1, 3}},
+			expected:  []emissionstypes.TopicId{2, 4},
+		},
+		{
+			name: "Deny list takes precedence over allow list",
+			discovery: lib.TopicDiscoveryConfig{
+				AllowTopicIds: []emissionstypes.TopicId{1, 2},
+				DenyTopicIds:  []emissionstypes.TopicId{2},
+			},
+			expected: []emissionstypes.TopicId{1},
+		},
+		{
+			name:      "Metadata regex",
+			discovery: lib.TopicDiscoveryConfig{MetadataRegex: "^(ETH|BTC) 10min"},
+			expected:  []emissionstypes.TopicId{1, 3},
+		},
+		{
+			name:      "Invalid metadata regex discovers nothing",
+			discovery: lib.TopicDiscoveryConfig{MetadataRegex: "("},
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, filterDiscoveredTopics(tt.discovery, topics))
+		})
+	}
+}
+
+func TestWorkerFromTemplateDoesNotShareParameters(t *testing.T) {
+	template := lib.WorkerConfig{
+		LoopSeconds: 10,
+		Parameters: map[string]string{
+			"InferenceEndpoint": "http://localhost:8000/inference/{TopicId}",
+		},
+		InferenceGuards: lib.InferenceGuardConfig{
+			FallbackParameters: map[string]string{"Token": "ETH"},
+		},
+	}
+
+	worker := workerFromTemplate(template, 5)
+	worker.Parameters["InferenceEndpoint"] = "changed"
+	worker.InferenceGuards.FallbackParameters["Token"] = "changed"
+
+	assert.Equal(t, emissionstypes.TopicId(5), worker.TopicId)
+	assert.Equal(t, int64(10), worker.LoopSeconds)
+	assert.Equal(t, "http://localhost:8000/inference/{TopicId}", template.Parameters["InferenceEndpoint"])
+	assert.Equal(t, "ETH", template.InferenceGuards.FallbackParameters["Token"])
+}
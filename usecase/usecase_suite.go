@@ -5,16 +5,46 @@ import (
 )
 
 type UseCaseSuite struct {
-	Node    lib.NodeConfig
-	Metrics lib.Metrics
+	Node       lib.NodeConfig
+	Metrics    lib.Metrics
+	Supervisor *ActorSupervisor
+	Notifier   *NonceNotifier // nil unless nonces are detected through the new block subscription
+	Ledger     *lib.SubmissionLedger
+	inferences inferenceHistory // checked against by the inference guards of the workers
 }
 
 // Static method to create a new UseCaseSuite
 func NewUseCaseSuite(userConfig lib.UserConfig) (*UseCaseSuite, error) {
 	userConfig.ValidateConfigAdapters()
+	if err := userConfig.ValidateConfigTopics(); err != nil {
+		return nil, err
+	}
+	if err := userConfig.ValidateConfigTopicDiscovery(); err != nil {
+		return nil, err
+	}
+	if err := userConfig.ValidateConfigReputerNonces(); err != nil {
+		return nil, err
+	}
+	if err := userConfig.ValidateConfigFees(); err != nil {
+		return nil, err
+	}
+	if err := userConfig.ValidateConfigInferenceGuards(); err != nil {
+		return nil, err
+	}
 	nodeConfig, err := userConfig.GenerateNodeConfig()
 	if err != nil {
 		return nil, err
 	}
-	return &UseCaseSuite{Node: *nodeConfig}, nil
+	suite := &UseCaseSuite{Node: *nodeConfig, Supervisor: NewActorSupervisor(nodeConfig.Supervisor)}
+	if nodeConfig.Subscription.Enabled {
+		suite.Notifier, err = NewNonceNotifier(nodeConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	suite.Ledger, err = lib.OpenSubmissionLedger(nodeConfig.Ledger.DbPath(), nodeConfig.Ledger.Retention())
+	if err != nil {
+		return nil, err
+	}
+	return suite, nil
 }
@@ -1,18 +1,37 @@
 package usecase
 
 import (
+	"allora_offchain_node/lib"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
-	"allora_offchain_node/lib"
 
 	emissionstypes "github.com/allora-network/allora-chain/x/emissions/types"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
-func (suite *UseCaseSuite) Wait(seconds int64) {
-	time.Sleep(time.Duration(seconds) * time.Second)
+// Wait for the given number of seconds, or until ctx is done.
+// Returns false if ctx is done, signalling the caller to stop.
+func (suite *UseCaseSuite) Wait(ctx context.Context, seconds int64) bool {
+	timer := time.NewTimer(time.Duration(seconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Wait until the actor should look for new nonces on its topic again: when woken by the nonce notifier if subscribed
+// to new blocks, else after the given number of seconds. Returns false if ctx is done, signalling the caller to stop.
+func (suite *UseCaseSuite) WaitForNonce(ctx context.Context, role ActorRole, topicId emissionstypes.TopicId, seconds int64) bool {
+	if suite.Notifier == nil {
+		return suite.Wait(ctx, seconds)
+	}
+	return suite.Notifier.Wait(ctx, role, topicId, seconds)
 }
 
 func IsEmpty(vb emissionstypes.ValueBundle) bool {